@@ -3,6 +3,7 @@ package ast
 import (
 	"bytes"
 	"monkey/token"
+	"strconv"
 	"strings"
 )
 
@@ -23,6 +24,7 @@ type Expression interface {
 
 type Program struct {
 	Statements []Statement
+	Comments   []*Comment // all comments in source order
 }
 
 func (programm *Program) TokenLiteral() string {
@@ -42,9 +44,20 @@ func (programm *Program) String() string {
 	return out.String()
 }
 
+type Comment struct {
+	Token token.Token // the token.COMMENT token
+}
+
+func (comment *Comment) TokenLiteral() string {
+	return comment.Token.Literal
+}
+func (comment *Comment) String() string {
+	return comment.Token.Literal
+}
+
 type LetStatement struct {
 	Token token.Token // the token.LET token
-	Name  *Identifier
+	Name  Pattern     // an identifier, or an array or hash pattern to destructure the value
 	Value Expression
 }
 
@@ -63,6 +76,73 @@ func (letStatement *LetStatement) String() string {
 	return out.String()
 }
 
+// A FunctionStatement declares a named function. The name is bound before
+// any statement of the enclosing block runs, so declarations can refer to
+// each other regardless of their order.
+type FunctionStatement struct {
+	Token    token.Token // the fn token
+	Name     *Identifier
+	Function *FunctionLiteral
+}
+
+func (functionStatement *FunctionStatement) statementNode() {}
+func (functionStatement *FunctionStatement) TokenLiteral() string {
+	return functionStatement.Token.Literal
+}
+func (functionStatement *FunctionStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(functionStatement.TokenLiteral() + " ")
+	out.WriteString(functionStatement.Name.String() + "(")
+	out.WriteString(parameterList(functionStatement.Function.Parameters))
+	out.WriteString(") ")
+	out.WriteString(functionStatement.Function.Body.String())
+
+	return out.String()
+}
+
+// An ImportStatement evaluates the module at Path once and binds its
+// exports to Alias as a namespace.
+type ImportStatement struct {
+	Token token.Token // the import token
+	Path  *StringLiteral
+	Alias *Identifier
+}
+
+func (importStatement *ImportStatement) statementNode() {}
+func (importStatement *ImportStatement) TokenLiteral() string {
+	return importStatement.Token.Literal
+}
+func (importStatement *ImportStatement) String() string {
+	return importStatement.TokenLiteral() + " " + importStatement.Path.String() + " as " + importStatement.Alias.String() + ";"
+}
+
+// An ExportStatement makes the names declared by a let statement or a
+// function declaration at the top level of a module visible to importers.
+type ExportStatement struct {
+	Token     token.Token // the export token
+	Statement Statement   // a *LetStatement or a *FunctionStatement
+}
+
+func (exportStatement *ExportStatement) statementNode() {}
+func (exportStatement *ExportStatement) TokenLiteral() string {
+	return exportStatement.Token.Literal
+}
+func (exportStatement *ExportStatement) String() string {
+	return exportStatement.TokenLiteral() + " " + exportStatement.Statement.String()
+}
+
+// Names returns the identifiers the exported statement declares.
+func (exportStatement *ExportStatement) Names() []*Identifier {
+	switch statement := exportStatement.Statement.(type) {
+	case *LetStatement:
+		return PatternNames(statement.Name)
+	case *FunctionStatement:
+		return []*Identifier{statement.Name}
+	}
+	return []*Identifier{}
+}
+
 type ReturnStatement struct {
 	Token token.Token // the token.RETURN token
 	Value Expression
@@ -82,6 +162,19 @@ func (returnStatement *ReturnStatement) String() string {
 	return out.String()
 }
 
+type ThrowStatement struct {
+	Token token.Token // the token.THROW token
+	Value Expression
+}
+
+func (throwStatement *ThrowStatement) statementNode() {}
+func (throwStatement *ThrowStatement) TokenLiteral() string {
+	return throwStatement.Token.Literal
+}
+func (throwStatement *ThrowStatement) String() string {
+	return throwStatement.TokenLiteral() + " " + throwStatement.Value.String() + ";"
+}
+
 type ExpressionStatement struct {
 	Token token.Token // the first token of the expression
 	Value Expression
@@ -98,6 +191,7 @@ func (expressionStatement *ExpressionStatement) String() string {
 type BlockStatement struct {
 	Token      token.Token // the { token
 	Statements []Statement
+	EndToken   token.Token // the } token
 }
 
 func (blockStatement *BlockStatement) statementNode() {}
@@ -143,10 +237,96 @@ func (ifExpression *IfExpression) String() string {
 	return out.String()
 }
 
+// A TryExpression has a catch or a finally block or both. Without catch,
+// Parameter and Catch are nil, without finally, Finally is nil.
+type TryExpression struct {
+	Token     token.Token // the try token
+	Block     *BlockStatement
+	Parameter *Identifier // the name of the caught error
+	Catch     *BlockStatement
+	Finally   *BlockStatement
+}
+
+func (tryExpression *TryExpression) expressionNode() {}
+func (tryExpression *TryExpression) TokenLiteral() string {
+	return tryExpression.Token.Literal
+}
+func (tryExpression *TryExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("try ")
+	out.WriteString(tryExpression.Block.String())
+
+	if tryExpression.Catch != nil {
+		out.WriteString(" catch (" + tryExpression.Parameter.String() + ") ")
+		out.WriteString(tryExpression.Catch.String())
+	}
+
+	if tryExpression.Finally != nil {
+		out.WriteString(" finally ")
+		out.WriteString(tryExpression.Finally.String())
+	}
+
+	return out.String()
+}
+
+type ConditionalExpression struct {
+	Token       token.Token // the ? token
+	Condition   Expression
+	Consequence Expression
+	Alternative Expression
+}
+
+func (conditionalExpression *ConditionalExpression) expressionNode() {}
+func (conditionalExpression *ConditionalExpression) TokenLiteral() string {
+	return conditionalExpression.Token.Literal
+}
+func (conditionalExpression *ConditionalExpression) String() string {
+	return "(" + conditionalExpression.Condition.String() +
+		" ? " + conditionalExpression.Consequence.String() +
+		" : " + conditionalExpression.Alternative.String() + ")"
+}
+
+// A MatchExpression evaluates the body of the first arm whose pattern
+// matches the subject.
+type MatchExpression struct {
+	Token    token.Token // the match token
+	Subject  Expression
+	Arms     []*MatchArm
+	EndToken token.Token // the } token
+}
+
+func (matchExpression *MatchExpression) expressionNode() {}
+func (matchExpression *MatchExpression) TokenLiteral() string {
+	return matchExpression.Token.Literal
+}
+func (matchExpression *MatchExpression) String() string {
+	arms := []string{}
+	for _, arm := range matchExpression.Arms {
+		arms = append(arms, arm.String())
+	}
+
+	return "match (" + matchExpression.Subject.String() + ") { " + strings.Join(arms, ", ") + " }"
+}
+
+type MatchArm struct {
+	Token   token.Token // the => token
+	Pattern Pattern
+	Body    Node // an Expression, or a *BlockStatement
+}
+
+func (matchArm *MatchArm) TokenLiteral() string {
+	return matchArm.Token.Literal
+}
+func (matchArm *MatchArm) String() string {
+	return matchArm.Pattern.String() + " => " + matchArm.Body.String()
+}
+
 type FunctionLiteral struct {
 	Token      token.Token // the fn token
-	Parameters []*Identifier
+	Parameters []*Parameter
 	Body       *BlockStatement
+	Source     string // source text of the function, empty if unknown
 }
 
 func (functionLiteral *FunctionLiteral) expressionNode() {}
@@ -156,19 +336,61 @@ func (functionLiteral *FunctionLiteral) TokenLiteral() string {
 func (functionLiteral *FunctionLiteral) String() string {
 	var out bytes.Buffer
 
-	parameters := []string{}
-	for _, parameter := range functionLiteral.Parameters {
-		parameters = append(parameters, parameter.String())
-	}
-
 	out.WriteString("fn(")
-	out.WriteString(strings.Join(parameters, ", "))
+	out.WriteString(parameterList(functionLiteral.Parameters))
 	out.WriteString(") ")
 	out.WriteString(functionLiteral.Body.String())
 
 	return out.String()
 }
 
+func parameterList(parameters []*Parameter) string {
+	list := []string{}
+	for _, parameter := range parameters {
+		list = append(list, parameter.String())
+	}
+	return strings.Join(list, ", ")
+}
+
+type Parameter struct {
+	Token   token.Token // the first token of the name, or the ... token of rest parameters
+	Name    Pattern     // an identifier, or an array or hash pattern to destructure the argument
+	Default Expression  // evaluated when the argument is missing, nil for required parameters
+	Rest    bool        // collects all remaining arguments into an array
+}
+
+func (parameter *Parameter) TokenLiteral() string {
+	return parameter.Token.Literal
+}
+func (parameter *Parameter) String() string {
+	if parameter.Rest {
+		return "..." + parameter.Name.String()
+	}
+
+	if parameter.Default != nil {
+		return parameter.Name.String() + " = " + parameter.Default.String()
+	}
+
+	return parameter.Name.String()
+}
+
+// Arity returns the number of required parameters and the maximum number
+// of arguments a function with the given parameters accepts. The maximum is
+// -1 if a rest parameter takes any number of arguments.
+func Arity(parameters []*Parameter) (required int, maximum int) {
+	for _, parameter := range parameters {
+		switch {
+		case parameter.Rest:
+			return required, -1
+		case parameter.Default == nil:
+			required += 1
+		}
+		maximum += 1
+	}
+
+	return required, maximum
+}
+
 type CallExpression struct {
 	Token     token.Token // the '(' token
 	Function  Expression  // Identifier or FunctionLiteral
@@ -264,6 +486,29 @@ func (int *IntegerLiteral) String() string {
 	return int.Token.Literal
 }
 
+type FloatLiteral struct {
+	Token token.Token // the token.FLOAT token
+	Value float64
+}
+
+func (float *FloatLiteral) expressionNode() {}
+func (float *FloatLiteral) TokenLiteral() string {
+	return float.Token.Literal
+}
+func (float *FloatLiteral) String() string {
+	return float.Token.Literal
+}
+
+// FormatFloat returns the source text of a float literal with value, which
+// always has a fraction, e.g. 2.0.
+func FormatFloat(value float64) string {
+	literal := strconv.FormatFloat(value, 'f', -1, 64)
+	if !strings.Contains(literal, ".") {
+		literal += ".0"
+	}
+	return literal
+}
+
 type Boolean struct {
 	Token token.Token
 	Value bool
@@ -276,3 +521,117 @@ func (boolean *Boolean) TokenLiteral() string {
 func (boolean *Boolean) String() string {
 	return boolean.Token.Literal
 }
+
+type NullLiteral struct {
+	Token token.Token // the token.NULL token
+}
+
+func (nullLiteral *NullLiteral) expressionNode() {}
+func (nullLiteral *NullLiteral) TokenLiteral() string {
+	return nullLiteral.Token.Literal
+}
+func (nullLiteral *NullLiteral) String() string {
+	return "null"
+}
+
+type StringLiteral struct {
+	Token token.Token // the token.STRING token
+	Value string      // the text with all escape sequences replaced
+}
+
+func (stringLiteral *StringLiteral) expressionNode() {}
+func (stringLiteral *StringLiteral) TokenLiteral() string {
+	return stringLiteral.Token.Literal
+}
+func (stringLiteral *StringLiteral) String() string {
+	return Quote(stringLiteral.Value)
+}
+
+var escapes = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+
+// Quote returns value as a string literal in Monkey syntax.
+func Quote(value string) string {
+	return `"` + escapes.Replace(value) + `"`
+}
+
+type ArrayLiteral struct {
+	Token    token.Token // the [ token
+	Elements []Expression
+}
+
+func (arrayLiteral *ArrayLiteral) expressionNode() {}
+func (arrayLiteral *ArrayLiteral) TokenLiteral() string {
+	return arrayLiteral.Token.Literal
+}
+func (arrayLiteral *ArrayLiteral) String() string {
+	elements := []string{}
+	for _, element := range arrayLiteral.Elements {
+		elements = append(elements, element.String())
+	}
+
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+type HashLiteral struct {
+	Token token.Token // the { token
+	Pairs []HashPair  // in source order
+}
+
+type HashPair struct {
+	Key   Expression
+	Value Expression
+}
+
+func (hashLiteral *HashLiteral) expressionNode() {}
+func (hashLiteral *HashLiteral) TokenLiteral() string {
+	return hashLiteral.Token.Literal
+}
+func (hashLiteral *HashLiteral) String() string {
+	pairs := []string{}
+	for _, pair := range hashLiteral.Pairs {
+		pairs = append(pairs, pair.Key.String()+": "+pair.Value.String())
+	}
+
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+type IndexExpression struct {
+	Token    token.Token // the [ token, or the ?[ token of optional index expressions
+	Left     Expression
+	Index    Expression
+	Optional bool // evaluates to null if Left is null
+}
+
+func (indexExpression *IndexExpression) expressionNode() {}
+func (indexExpression *IndexExpression) TokenLiteral() string {
+	return indexExpression.Token.Literal
+}
+func (indexExpression *IndexExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(indexExpression.Left.String())
+	if indexExpression.Optional {
+		out.WriteString("?")
+	}
+	out.WriteString("[")
+	out.WriteString(indexExpression.Index.String())
+	out.WriteString("])")
+
+	return out.String()
+}
+
+type MemberExpression struct {
+	Token    token.Token // the . token, or the ?. token of optional member expressions
+	Object   Expression
+	Property *Identifier
+	Optional bool // evaluates to null if Object is null
+}
+
+func (memberExpression *MemberExpression) expressionNode() {}
+func (memberExpression *MemberExpression) TokenLiteral() string {
+	return memberExpression.Token.Literal
+}
+func (memberExpression *MemberExpression) String() string {
+	return "(" + memberExpression.Object.String() + memberExpression.Token.Literal + memberExpression.Property.String() + ")"
+}
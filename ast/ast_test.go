@@ -162,20 +162,47 @@ func TestString(t *testing.T) {
 						Type:    token.FUNCTION,
 						Literal: "fn",
 					},
-					Parameters: []*Identifier{
+					Parameters: []*Parameter{
 						{
 							Token: token.Token{
 								Type:    token.IDENT,
 								Literal: "a",
 							},
-							Value: "a",
+							Name: &Identifier{
+								Token: token.Token{
+									Type:    token.IDENT,
+									Literal: "a",
+								},
+								Value: "a",
+							},
 						},
 						{
 							Token: token.Token{
 								Type:    token.IDENT,
 								Literal: "b",
 							},
-							Value: "b",
+							Name: &Identifier{
+								Token: token.Token{
+									Type:    token.IDENT,
+									Literal: "b",
+								},
+								Value: "b",
+							},
+							Default: newIntegerLiteral(2),
+						},
+						{
+							Token: token.Token{
+								Type:    token.ELLIPSIS,
+								Literal: "...",
+							},
+							Name: &Identifier{
+								Token: token.Token{
+									Type:    token.IDENT,
+									Literal: "c",
+								},
+								Value: "c",
+							},
+							Rest: true,
 						},
 					},
 					Body: &BlockStatement{
@@ -195,7 +222,7 @@ func TestString(t *testing.T) {
 					},
 				},
 			},
-			"fn(a, b) { return 3; }",
+			"fn(a, b = 2, ...c) { return 3; }",
 		},
 		{
 			&ExpressionStatement{
@@ -0,0 +1,144 @@
+package ast
+
+// FreeVariables returns the names function refers to without binding them
+// itself, in the order of their first use. These are the names it looks up
+// in the environment it is defined in.
+func FreeVariables(function *FunctionLiteral) []string {
+	finder := &freeVariableFinder{names: []string{}, seen: map[string]bool{}}
+	finder.function(function, nil)
+
+	return finder.names
+}
+
+type freeScope struct {
+	outer *freeScope
+	names map[string]bool
+}
+
+func newFreeScope(outer *freeScope) *freeScope {
+	return &freeScope{outer: outer, names: map[string]bool{}}
+}
+
+func (scope *freeScope) binds(name string) bool {
+	for ; scope != nil; scope = scope.outer {
+		if scope.names[name] {
+			return true
+		}
+	}
+	return false
+}
+
+type freeVariableFinder struct {
+	names []string
+	seen  map[string]bool
+}
+
+func (finder *freeVariableFinder) function(function *FunctionLiteral, outer *freeScope) {
+	scope := newFreeScope(outer)
+
+	for _, parameter := range function.Parameters {
+		// Defaults can refer to the parameters before them
+		if parameter.Default != nil {
+			finder.node(parameter.Default, scope)
+		}
+		if !isNilNode(parameter.Name) {
+			bindPattern(parameter.Name, scope)
+		}
+	}
+
+	if function.Body != nil {
+		finder.node(function.Body, scope)
+	}
+}
+
+func (finder *freeVariableFinder) node(node Node, scope *freeScope) {
+	switch node := node.(type) {
+	case *Identifier:
+		if !scope.binds(node.Value) && !finder.seen[node.Value] {
+			finder.seen[node.Value] = true
+			finder.names = append(finder.names, node.Value)
+		}
+
+	case *FunctionLiteral:
+		finder.function(node, scope)
+
+	case *MemberExpression:
+		// The property is a key of the object, not a variable
+		if !isNilNode(node.Object) {
+			finder.node(node.Object, scope)
+		}
+
+	case *BlockStatement:
+		blockScope := newFreeScope(scope)
+
+		for _, statement := range node.Statements {
+			if declaration, ok := statement.(*FunctionStatement); ok && declaration.Name != nil {
+				blockScope.names[declaration.Name.Value] = true
+			}
+		}
+
+		for _, statement := range node.Statements {
+			finder.node(statement, blockScope)
+		}
+
+	case *MatchExpression:
+		if !isNilNode(node.Subject) {
+			finder.node(node.Subject, scope)
+		}
+
+		for _, arm := range node.Arms {
+			armScope := newFreeScope(scope)
+			if !isNilNode(arm.Pattern) {
+				bindPattern(arm.Pattern, armScope)
+			}
+			if !isNilNode(arm.Body) {
+				finder.node(arm.Body, armScope)
+			}
+		}
+
+	case *TryExpression:
+		for _, block := range []*BlockStatement{node.Block, node.Finally} {
+			if block != nil {
+				finder.node(block, scope)
+			}
+		}
+
+		if node.Catch != nil {
+			catchScope := newFreeScope(scope)
+			if node.Parameter != nil {
+				catchScope.names[node.Parameter.Value] = true
+			}
+			finder.node(node.Catch, catchScope)
+		}
+
+	case *LetStatement:
+		if !isNilNode(node.Value) {
+			finder.node(node.Value, scope)
+		}
+		if !isNilNode(node.Name) {
+			bindPattern(node.Name, scope)
+		}
+
+	case *ImportStatement:
+		if node.Alias != nil {
+			scope.names[node.Alias.Value] = true
+		}
+
+	case *FunctionStatement:
+		if node.Function != nil {
+			finder.function(node.Function, scope)
+		}
+
+	default:
+		for _, child := range children(node) {
+			finder.node(child, scope)
+		}
+	}
+}
+
+// bindPattern adds the names pattern binds to scope.
+func bindPattern(pattern Pattern, scope *freeScope) {
+	for _, name := range PatternNames(pattern) {
+		scope.names[name.Value] = true
+	}
+}
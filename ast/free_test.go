@@ -0,0 +1,76 @@
+package ast_test
+
+import (
+	"monkey/ast"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFreeVariables(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			"fn(a, b) { a + b }",
+			[]string{},
+		},
+		{
+			"fn(a) { a + x + y + x }",
+			[]string{"x", "y"},
+		},
+		{
+			"fn(a, b = a + c) { b }",
+			[]string{"c"},
+		},
+		{
+			"fn() { let a = x; let x = a; x }",
+			[]string{"x"},
+		},
+		{
+			"fn() { if (c) { let a = 1; a } else { a } }",
+			[]string{"c", "a"},
+		},
+		{
+			"fn() { fn(b) { a + b } }",
+			[]string{"a"},
+		},
+		{
+			"fn() { g(); fn g() { f() } }",
+			[]string{"f"},
+		},
+		{
+			`fn(person) { person.name ?? {"name": name}?.name }`,
+			[]string{"name"},
+		},
+		{
+			"fn(list) { [list[0], len(list)] }",
+			[]string{"len"},
+		},
+		{
+			"fn(v) { match (v) { [a, ...rest] => a + rest + b, {c} => c, x => { x + d } } }",
+			[]string{"b", "d"},
+		},
+		{
+			`fn() { import "lib.mk" as lib; [lib.a, other] }`,
+			[]string{"other"},
+		},
+		{
+			"fn() { match (a) { a => a } }",
+			[]string{"a"},
+		},
+		{
+			"fn([a, ...b], {c} = d) { let [e, {f}] = a; [b, c, e, f, g] }",
+			[]string{"d", "g"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			function := parse(t, testCase.input).Statements[0].(*ast.ExpressionStatement).Value.(*ast.FunctionLiteral)
+
+			assert.Equal(t, testCase.expected, ast.FreeVariables(function))
+		})
+	}
+}
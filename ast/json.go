@@ -0,0 +1,997 @@
+package ast
+
+import (
+	"encoding/json"
+	"fmt"
+	"monkey/token"
+	"reflect"
+	"strconv"
+)
+
+// The JSON encoding represents every node as an object with a "kind"
+// discriminator holding the node's type name and a "pos" holding the
+// position of the node's token. All other keys depend on the kind, e.g.
+//
+//	{"kind": "Identifier", "pos": {"line": 1, "column": 5}, "value": "x"}
+//
+// Missing child nodes are encoded as null.
+
+type jsonPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type jsonToken struct {
+	Type    token.TokenType `json:"type"`
+	Literal string          `json:"literal"`
+}
+
+type jsonObject = map[string]any
+
+// EncodeJSON returns the JSON encoding of node.
+func EncodeJSON(node Node) ([]byte, error) {
+	return json.Marshal(encodeNode(node))
+}
+
+// DecodeJSON reconstructs a node from its JSON encoding.
+func DecodeJSON(data []byte) (Node, error) {
+	return decodeNode(data)
+}
+
+// DecodeProgramJSON reconstructs a program from its JSON encoding.
+func DecodeProgramJSON(data []byte) (*Program, error) {
+	node, err := decodeNode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	program, ok := node.(*Program)
+	if !ok {
+		return nil, fmt.Errorf("expected Program, got %s", nodeKind(node))
+	}
+
+	return program, nil
+}
+
+func nodeKind(node Node) string {
+	if isNilNode(node) {
+		return "null"
+	}
+	return reflect.TypeOf(node).Elem().Name()
+}
+
+func isNilNode(node Node) bool {
+	if node == nil {
+		return true
+	}
+	value := reflect.ValueOf(node)
+	return value.Kind() == reflect.Pointer && value.IsNil()
+}
+
+func encodePosition(tok token.Token) jsonPosition {
+	return jsonPosition{Line: tok.Position.Line, Column: tok.Position.Column}
+}
+
+func encodeNode(node Node) any {
+	if isNilNode(node) {
+		return nil
+	}
+
+	object := jsonObject{"kind": nodeKind(node)}
+
+	switch node := node.(type) {
+	case *Program:
+		object["statements"] = encodeStatements(node.Statements)
+
+		if len(node.Comments) > 0 {
+			comments := []any{}
+			for _, comment := range node.Comments {
+				comments = append(comments, encodeNode(comment))
+			}
+			object["comments"] = comments
+		}
+
+	case *Comment:
+		object["pos"] = encodePosition(node.Token)
+		object["text"] = node.Token.Literal
+
+	case *LetStatement:
+		object["pos"] = encodePosition(node.Token)
+		object["name"] = encodeNode(node.Name)
+		object["value"] = encodeNode(node.Value)
+
+	case *FunctionStatement:
+		object["pos"] = encodePosition(node.Token)
+		object["name"] = encodeNode(node.Name)
+		object["function"] = encodeNode(node.Function)
+
+	case *ImportStatement:
+		object["pos"] = encodePosition(node.Token)
+		object["path"] = encodeNode(node.Path)
+		object["alias"] = encodeNode(node.Alias)
+
+	case *ExportStatement:
+		object["pos"] = encodePosition(node.Token)
+		object["statement"] = encodeNode(node.Statement)
+
+	case *ReturnStatement:
+		object["pos"] = encodePosition(node.Token)
+		object["value"] = encodeNode(node.Value)
+
+	case *ThrowStatement:
+		object["pos"] = encodePosition(node.Token)
+		object["value"] = encodeNode(node.Value)
+
+	case *ExpressionStatement:
+		// The first token of an expression statement is not necessarily
+		// part of the expression (e.g. an opening parenthesis).
+		object["pos"] = encodePosition(node.Token)
+		object["token"] = jsonToken{Type: node.Token.Type, Literal: node.Token.Literal}
+		object["value"] = encodeNode(node.Value)
+
+	case *BlockStatement:
+		object["pos"] = encodePosition(node.Token)
+		object["statements"] = encodeStatements(node.Statements)
+		object["end"] = encodePosition(node.EndToken)
+
+	case *IfExpression:
+		object["pos"] = encodePosition(node.Token)
+		object["condition"] = encodeNode(node.Condition)
+		object["consequence"] = encodeNode(node.Consequence)
+		object["alternative"] = encodeNode(node.Alternative)
+
+	case *TryExpression:
+		object["pos"] = encodePosition(node.Token)
+		object["block"] = encodeNode(node.Block)
+		object["parameter"] = encodeNode(node.Parameter)
+		object["catch"] = encodeNode(node.Catch)
+		object["finally"] = encodeNode(node.Finally)
+
+	case *ConditionalExpression:
+		object["pos"] = encodePosition(node.Token)
+		object["condition"] = encodeNode(node.Condition)
+		object["consequence"] = encodeNode(node.Consequence)
+		object["alternative"] = encodeNode(node.Alternative)
+
+	case *MatchExpression:
+		arms := []any{}
+		for _, arm := range node.Arms {
+			arms = append(arms, encodeNode(arm))
+		}
+
+		object["pos"] = encodePosition(node.Token)
+		object["subject"] = encodeNode(node.Subject)
+		object["arms"] = arms
+		object["end"] = encodePosition(node.EndToken)
+
+	case *MatchArm:
+		object["pos"] = encodePosition(node.Token)
+		object["pattern"] = encodeNode(node.Pattern)
+		object["body"] = encodeNode(node.Body)
+
+	case *WildcardPattern:
+		object["pos"] = encodePosition(node.Token)
+
+	case *LiteralPattern:
+		object["pos"] = encodePosition(node.Token)
+		object["value"] = encodeNode(node.Value)
+
+	case *ArrayPattern:
+		elements := []any{}
+		for _, element := range node.Elements {
+			elements = append(elements, encodeNode(element))
+		}
+
+		object["pos"] = encodePosition(node.Token)
+		object["elements"] = elements
+		object["rest"] = encodeNode(node.Rest)
+
+	case *HashPattern:
+		pairs := []any{}
+		for _, pair := range node.Pairs {
+			pairs = append(pairs, jsonObject{
+				"key":       encodeNode(pair.Key),
+				"value":     encodeNode(pair.Value),
+				"shorthand": pair.Shorthand,
+			})
+		}
+
+		object["pos"] = encodePosition(node.Token)
+		object["pairs"] = pairs
+
+	case *FunctionLiteral:
+		parameters := []any{}
+		for _, parameter := range node.Parameters {
+			parameters = append(parameters, encodeNode(parameter))
+		}
+
+		object["pos"] = encodePosition(node.Token)
+		object["parameters"] = parameters
+		object["body"] = encodeNode(node.Body)
+
+		if node.Source != "" {
+			object["source"] = node.Source
+		}
+
+	case *Parameter:
+		object["pos"] = encodePosition(node.Token)
+		object["name"] = encodeNode(node.Name)
+		object["default"] = encodeNode(node.Default)
+		object["rest"] = node.Rest
+
+	case *ArrayLiteral:
+		elements := []any{}
+		for _, element := range node.Elements {
+			elements = append(elements, encodeNode(element))
+		}
+
+		object["pos"] = encodePosition(node.Token)
+		object["elements"] = elements
+
+	case *IndexExpression:
+		object["pos"] = encodePosition(node.Token)
+		object["left"] = encodeNode(node.Left)
+		object["index"] = encodeNode(node.Index)
+
+		if node.Optional {
+			object["optional"] = true
+		}
+
+	case *MemberExpression:
+		object["pos"] = encodePosition(node.Token)
+		object["object"] = encodeNode(node.Object)
+		object["property"] = encodeNode(node.Property)
+
+		if node.Optional {
+			object["optional"] = true
+		}
+
+	case *HashLiteral:
+		pairs := []any{}
+		for _, pair := range node.Pairs {
+			pairs = append(pairs, jsonObject{
+				"key":   encodeNode(pair.Key),
+				"value": encodeNode(pair.Value),
+			})
+		}
+
+		object["pos"] = encodePosition(node.Token)
+		object["pairs"] = pairs
+
+	case *CallExpression:
+		arguments := []any{}
+		for _, argument := range node.Arguments {
+			arguments = append(arguments, encodeNode(argument))
+		}
+
+		object["pos"] = encodePosition(node.Token)
+		object["function"] = encodeNode(node.Function)
+		object["arguments"] = arguments
+
+	case *PrefixExpression:
+		object["pos"] = encodePosition(node.Token)
+		object["operator"] = node.Operator
+		object["right"] = encodeNode(node.Right)
+
+	case *InfixExpression:
+		object["pos"] = encodePosition(node.Token)
+		object["operator"] = node.Operator
+		object["left"] = encodeNode(node.Left)
+		object["right"] = encodeNode(node.Right)
+
+	case *Identifier:
+		object["pos"] = encodePosition(node.Token)
+		object["value"] = node.Value
+
+	case *IntegerLiteral:
+		object["pos"] = encodePosition(node.Token)
+		object["value"] = node.Value
+
+	case *FloatLiteral:
+		object["pos"] = encodePosition(node.Token)
+		object["value"] = node.Value
+
+	case *StringLiteral:
+		object["pos"] = encodePosition(node.Token)
+		object["value"] = node.Value
+
+	case *NullLiteral:
+		object["pos"] = encodePosition(node.Token)
+
+	case *Boolean:
+		object["pos"] = encodePosition(node.Token)
+		object["value"] = node.Value
+	}
+
+	return object
+}
+
+func encodeStatements(statements []Statement) []any {
+	encoded := []any{}
+	for _, statement := range statements {
+		encoded = append(encoded, encodeNode(statement))
+	}
+	return encoded
+}
+
+type jsonDecoder struct {
+	fields map[string]json.RawMessage
+	err    error
+}
+
+func decodeNode(data []byte) (Node, error) {
+	if isNull(data) {
+		return nil, nil
+	}
+
+	decoder := &jsonDecoder{}
+	if err := json.Unmarshal(data, &decoder.fields); err != nil {
+		return nil, err
+	}
+
+	var kind string
+	decoder.field("kind", &kind)
+
+	node := decoder.decode(kind)
+	if decoder.err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", kind, decoder.err)
+	}
+
+	return node, nil
+}
+
+func isNull(data []byte) bool {
+	return len(data) == 0 || string(data) == "null"
+}
+
+func (decoder *jsonDecoder) decode(kind string) Node {
+	switch kind {
+	case "Program":
+		program := &Program{}
+		if statements := decoder.statements("statements"); len(statements) > 0 {
+			program.Statements = statements
+		}
+
+		if _, ok := decoder.fields["comments"]; ok {
+			for _, node := range decoder.nodes("comments") {
+				comment, ok := node.(*Comment)
+				if !ok {
+					decoder.fail("expected comment in \"comments\", got %s", nodeKind(node))
+					continue
+				}
+				program.Comments = append(program.Comments, comment)
+			}
+		}
+
+		return program
+
+	case "Comment":
+		var text string
+		decoder.field("text", &text)
+
+		return &Comment{
+			Token: decoder.token(token.COMMENT, text),
+		}
+
+	case "LetStatement":
+		return &LetStatement{
+			Token: decoder.token(token.LET, "let"),
+			Name:  decoder.pattern("name"),
+			Value: decoder.expression("value"),
+		}
+
+	case "FunctionStatement":
+		return &FunctionStatement{
+			Token:    decoder.token(token.FUNCTION, "fn"),
+			Name:     decoder.identifier("name"),
+			Function: decoder.function("function"),
+		}
+
+	case "ImportStatement":
+		path, ok := decoder.node("path").(*StringLiteral)
+		if !ok {
+			decoder.fail("expected \"path\" to be StringLiteral")
+		}
+
+		return &ImportStatement{
+			Token: decoder.token(token.IMPORT, "import"),
+			Path:  path,
+			Alias: decoder.identifier("alias"),
+		}
+
+	case "ExportStatement":
+		statement := decoder.node("statement")
+
+		switch statement.(type) {
+		case *LetStatement, *FunctionStatement:
+		default:
+			decoder.fail("expected \"statement\" to be LetStatement or FunctionStatement, got %s", nodeKind(statement))
+		}
+
+		exportStatement := &ExportStatement{Token: decoder.token(token.EXPORT, "export")}
+		exportStatement.Statement, _ = statement.(Statement)
+
+		return exportStatement
+
+	case "ReturnStatement":
+		return &ReturnStatement{
+			Token: decoder.token(token.RETURN, "return"),
+			Value: decoder.expression("value"),
+		}
+
+	case "ThrowStatement":
+		return &ThrowStatement{
+			Token: decoder.token(token.THROW, "throw"),
+			Value: decoder.expression("value"),
+		}
+
+	case "ExpressionStatement":
+		var tok jsonToken
+		decoder.field("token", &tok)
+
+		return &ExpressionStatement{
+			Token: decoder.token(tok.Type, tok.Literal),
+			Value: decoder.expression("value"),
+		}
+
+	case "BlockStatement":
+		return &BlockStatement{
+			Token:      decoder.token(token.LBRACE, "{"),
+			Statements: decoder.statements("statements"),
+			EndToken:   decoder.positionedToken("end", token.RBRACE, "}"),
+		}
+
+	case "IfExpression":
+		return &IfExpression{
+			Token:       decoder.token(token.IF, "if"),
+			Condition:   decoder.expression("condition"),
+			Consequence: decoder.block("consequence"),
+			Alternative: decoder.optionalBlock("alternative"),
+		}
+
+	case "TryExpression":
+		tryExpression := &TryExpression{
+			Token:     decoder.token(token.TRY, "try"),
+			Block:     decoder.block("block"),
+			Parameter: decoder.optionalIdentifier("parameter"),
+			Catch:     decoder.optionalBlock("catch"),
+			Finally:   decoder.optionalBlock("finally"),
+		}
+
+		if tryExpression.Catch == nil && tryExpression.Finally == nil {
+			decoder.fail("expected \"catch\" or \"finally\"")
+		}
+		if (tryExpression.Parameter == nil) != (tryExpression.Catch == nil) {
+			decoder.fail("expected both \"parameter\" and \"catch\" or neither")
+		}
+
+		return tryExpression
+
+	case "ConditionalExpression":
+		return &ConditionalExpression{
+			Token:       decoder.token(token.QUESTION, "?"),
+			Condition:   decoder.expression("condition"),
+			Consequence: decoder.expression("consequence"),
+			Alternative: decoder.expression("alternative"),
+		}
+
+	case "MatchExpression":
+		arms := []*MatchArm{}
+		for _, node := range decoder.nodes("arms") {
+			arm, ok := node.(*MatchArm)
+			if !ok {
+				decoder.fail("expected arm to be MatchArm, got %s", nodeKind(node))
+			}
+			arms = append(arms, arm)
+		}
+
+		return &MatchExpression{
+			Token:    decoder.token(token.MATCH, "match"),
+			Subject:  decoder.expression("subject"),
+			Arms:     arms,
+			EndToken: decoder.positionedToken("end", token.RBRACE, "}"),
+		}
+
+	case "MatchArm":
+		body := decoder.node("body")
+		if _, ok := body.(*BlockStatement); !ok {
+			body = decoder.asExpression(body)
+		}
+
+		return &MatchArm{
+			Token:   decoder.token(token.ARROW, "=>"),
+			Pattern: decoder.pattern("pattern"),
+			Body:    body,
+		}
+
+	case "WildcardPattern":
+		return &WildcardPattern{
+			Token: decoder.token(token.IDENT, "_"),
+		}
+
+	case "LiteralPattern":
+		value := decoder.expression("value")
+
+		var tok token.Token
+		switch value := value.(type) {
+		case *PrefixExpression:
+			tok = value.Token
+		case *IntegerLiteral:
+			tok = value.Token
+		case *FloatLiteral:
+			tok = value.Token
+		case *StringLiteral:
+			tok = value.Token
+		case *Boolean:
+			tok = value.Token
+		case *NullLiteral:
+			tok = value.Token
+		default:
+			decoder.fail("expected \"value\" to be a literal, got %s", nodeKind(value))
+		}
+
+		return &LiteralPattern{
+			Token: tok,
+			Value: value,
+		}
+
+	case "ArrayPattern":
+		elements := []Pattern{}
+		for _, element := range decoder.nodes("elements") {
+			elements = append(elements, decoder.asPattern(element))
+		}
+
+		return &ArrayPattern{
+			Token:    decoder.token(token.LBRACKET, "["),
+			Elements: elements,
+			Rest:     decoder.optionalPattern("rest"),
+		}
+
+	case "HashPattern":
+		var rawPairs []map[string]json.RawMessage
+		decoder.field("pairs", &rawPairs)
+
+		pairs := []HashPatternPair{}
+		for _, rawPair := range rawPairs {
+			pair := &jsonDecoder{fields: rawPair}
+
+			var shorthand bool
+			pair.field("shorthand", &shorthand)
+
+			pairs = append(pairs, HashPatternPair{
+				Key:       pair.expression("key"),
+				Value:     pair.pattern("value"),
+				Shorthand: shorthand,
+			})
+
+			if pair.err != nil {
+				decoder.fail("pairs: %s", pair.err)
+			}
+		}
+
+		return &HashPattern{
+			Token: decoder.token(token.LBRACE, "{"),
+			Pairs: pairs,
+		}
+
+	case "FunctionLiteral":
+		parameters := []*Parameter{}
+		for _, node := range decoder.nodes("parameters") {
+			parameter, ok := node.(*Parameter)
+			if !ok {
+				decoder.fail("expected parameter to be Parameter, got %s", nodeKind(node))
+			}
+			parameters = append(parameters, parameter)
+		}
+
+		var source string
+		decoder.optionalField("source", &source)
+
+		return &FunctionLiteral{
+			Token:      decoder.token(token.FUNCTION, "fn"),
+			Parameters: parameters,
+			Body:       decoder.block("body"),
+			Source:     source,
+		}
+
+	case "Parameter":
+		var rest bool
+		decoder.field("rest", &rest)
+
+		name := decoder.pattern("name")
+		tok := decoder.token(token.ELLIPSIS, "...")
+
+		if !rest {
+			switch name := name.(type) {
+			case *Identifier:
+				tok = decoder.token(token.IDENT, name.Value)
+			case *ArrayPattern:
+				tok = decoder.token(token.LBRACKET, "[")
+			case *HashPattern:
+				tok = decoder.token(token.LBRACE, "{")
+			}
+		}
+
+		return &Parameter{
+			Token:   tok,
+			Name:    name,
+			Default: decoder.optionalExpression("default"),
+			Rest:    rest,
+		}
+
+	case "ArrayLiteral":
+		elements := []Expression{}
+		for _, element := range decoder.nodes("elements") {
+			elements = append(elements, decoder.asExpression(element))
+		}
+
+		return &ArrayLiteral{
+			Token:    decoder.token(token.LBRACKET, "["),
+			Elements: elements,
+		}
+
+	case "IndexExpression":
+		var optional bool
+		decoder.optionalField("optional", &optional)
+
+		tok := decoder.token(token.LBRACKET, "[")
+		if optional {
+			tok = decoder.token(token.QUESTION_BRACKET, "?[")
+		}
+
+		return &IndexExpression{
+			Token:    tok,
+			Left:     decoder.expression("left"),
+			Index:    decoder.expression("index"),
+			Optional: optional,
+		}
+
+	case "MemberExpression":
+		var optional bool
+		decoder.optionalField("optional", &optional)
+
+		tok := decoder.token(token.DOT, ".")
+		if optional {
+			tok = decoder.token(token.QUESTION_DOT, "?.")
+		}
+
+		return &MemberExpression{
+			Token:    tok,
+			Object:   decoder.expression("object"),
+			Property: decoder.identifier("property"),
+			Optional: optional,
+		}
+
+	case "HashLiteral":
+		var rawPairs []map[string]json.RawMessage
+		decoder.field("pairs", &rawPairs)
+
+		pairs := []HashPair{}
+		for _, rawPair := range rawPairs {
+			pair := &jsonDecoder{fields: rawPair}
+			pairs = append(pairs, HashPair{
+				Key:   pair.expression("key"),
+				Value: pair.expression("value"),
+			})
+
+			if pair.err != nil {
+				decoder.fail("pairs: %s", pair.err)
+			}
+		}
+
+		return &HashLiteral{
+			Token: decoder.token(token.LBRACE, "{"),
+			Pairs: pairs,
+		}
+
+	case "CallExpression":
+		arguments := []Expression{}
+		for _, argument := range decoder.nodes("arguments") {
+			arguments = append(arguments, decoder.asExpression(argument))
+		}
+
+		return &CallExpression{
+			Token:     decoder.token(token.LPAREN, "("),
+			Function:  decoder.expression("function"),
+			Arguments: arguments,
+		}
+
+	case "PrefixExpression":
+		var operator string
+		decoder.field("operator", &operator)
+
+		return &PrefixExpression{
+			Token:    decoder.operatorToken(operator),
+			Operator: operator,
+			Right:    decoder.expression("right"),
+		}
+
+	case "InfixExpression":
+		var operator string
+		decoder.field("operator", &operator)
+
+		return &InfixExpression{
+			Token:    decoder.operatorToken(operator),
+			Operator: operator,
+			Left:     decoder.expression("left"),
+			Right:    decoder.expression("right"),
+		}
+
+	case "Identifier":
+		var value string
+		decoder.field("value", &value)
+
+		return &Identifier{
+			Token: decoder.token(token.IDENT, value),
+			Value: value,
+		}
+
+	case "StringLiteral":
+		var value string
+		decoder.field("value", &value)
+
+		quoted := Quote(value)
+
+		return &StringLiteral{
+			Token: decoder.token(token.STRING, quoted[1:len(quoted)-1]),
+			Value: value,
+		}
+
+	case "NullLiteral":
+		return &NullLiteral{
+			Token: decoder.token(token.NULL, "null"),
+		}
+
+	case "IntegerLiteral":
+		var value int64
+		decoder.field("value", &value)
+
+		return &IntegerLiteral{
+			Token: decoder.token(token.INT, strconv.FormatInt(value, 10)),
+			Value: value,
+		}
+
+	case "FloatLiteral":
+		var value float64
+		decoder.field("value", &value)
+
+		return &FloatLiteral{
+			Token: decoder.token(token.FLOAT, FormatFloat(value)),
+			Value: value,
+		}
+
+	case "Boolean":
+		var value bool
+		decoder.field("value", &value)
+
+		tokenType := token.FALSE
+		if value {
+			tokenType = token.TRUE
+		}
+
+		return &Boolean{
+			Token: decoder.token(tokenType, strconv.FormatBool(value)),
+			Value: value,
+		}
+	}
+
+	decoder.fail("unknown kind %q", kind)
+	return nil
+}
+
+func (decoder *jsonDecoder) fail(format string, a ...any) {
+	if decoder.err == nil {
+		decoder.err = fmt.Errorf(format, a...)
+	}
+}
+
+func (decoder *jsonDecoder) field(name string, target any) {
+	raw, ok := decoder.fields[name]
+	if !ok {
+		decoder.fail("missing field %q", name)
+		return
+	}
+
+	if err := json.Unmarshal(raw, target); err != nil {
+		decoder.fail("field %q: %s", name, err)
+	}
+}
+
+// optionalField decodes a field which is omitted for its zero value.
+func (decoder *jsonDecoder) optionalField(name string, target any) {
+	if _, ok := decoder.fields[name]; ok {
+		decoder.field(name, target)
+	}
+}
+
+func (decoder *jsonDecoder) token(tokenType token.TokenType, literal string) token.Token {
+	return decoder.positionedToken("pos", tokenType, literal)
+}
+
+func (decoder *jsonDecoder) positionedToken(name string, tokenType token.TokenType, literal string) token.Token {
+	var position jsonPosition
+	decoder.field(name, &position)
+
+	return token.Token{
+		Type:     tokenType,
+		Literal:  literal,
+		Position: token.Position{Line: position.Line, Column: position.Column},
+	}
+}
+
+func (decoder *jsonDecoder) operatorToken(operator string) token.Token {
+	if tokenType, ok := token.LookupTwoCharToken(operator); ok {
+		return decoder.token(tokenType, operator)
+	}
+
+	if len(operator) == 1 {
+		if tokenType, ok := token.LookupOneCharToken(operator[0]); ok {
+			return decoder.token(tokenType, operator)
+		}
+	}
+
+	decoder.fail("unknown operator %q", operator)
+	return token.Token{}
+}
+
+func (decoder *jsonDecoder) node(name string) Node {
+	var raw json.RawMessage
+	decoder.field(name, &raw)
+
+	if decoder.err != nil {
+		return nil
+	}
+
+	node, err := decodeNode(raw)
+	if err != nil {
+		decoder.fail("field %q: %s", name, err)
+	}
+
+	return node
+}
+
+func (decoder *jsonDecoder) nodes(name string) []Node {
+	var raws []json.RawMessage
+	decoder.field(name, &raws)
+
+	nodes := []Node{}
+	for _, raw := range raws {
+		node, err := decodeNode(raw)
+		if err != nil {
+			decoder.fail("field %q: %s", name, err)
+		}
+		nodes = append(nodes, node)
+	}
+
+	return nodes
+}
+
+func (decoder *jsonDecoder) statements(name string) []Statement {
+	statements := []Statement{}
+
+	for _, node := range decoder.nodes(name) {
+		statement, ok := node.(Statement)
+		if !ok {
+			decoder.fail("expected statement in %q, got %s", name, nodeKind(node))
+			continue
+		}
+		statements = append(statements, statement)
+	}
+
+	return statements
+}
+
+// missing fails unless the required field name was decoded, if it is null
+// the field is missing.
+func (decoder *jsonDecoder) missing(name string, node Node) bool {
+	if isNilNode(node) {
+		decoder.fail("missing %q", name)
+		return true
+	}
+	return false
+}
+
+func (decoder *jsonDecoder) asExpression(node Node) Expression {
+	expression, ok := node.(Expression)
+	if !ok {
+		decoder.fail("expected expression, got %s", nodeKind(node))
+	}
+
+	return expression
+}
+
+func (decoder *jsonDecoder) expression(name string) Expression {
+	node := decoder.node(name)
+	if decoder.missing(name, node) {
+		return nil
+	}
+	return decoder.asExpression(node)
+}
+
+// optionalExpression decodes an expression field which is null if omitted.
+func (decoder *jsonDecoder) optionalExpression(name string) Expression {
+	node := decoder.node(name)
+	if node == nil {
+		return nil
+	}
+	return decoder.asExpression(node)
+}
+
+func (decoder *jsonDecoder) asPattern(node Node) Pattern {
+	pattern, ok := node.(Pattern)
+	if !ok {
+		decoder.fail("expected pattern, got %s", nodeKind(node))
+	}
+
+	return pattern
+}
+
+func (decoder *jsonDecoder) pattern(name string) Pattern {
+	node := decoder.node(name)
+	if decoder.missing(name, node) {
+		return nil
+	}
+	return decoder.asPattern(node)
+}
+
+func (decoder *jsonDecoder) optionalPattern(name string) Pattern {
+	node := decoder.node(name)
+	if node == nil {
+		return nil
+	}
+	return decoder.asPattern(node)
+}
+
+func (decoder *jsonDecoder) identifier(name string) *Identifier {
+	identifier := decoder.optionalIdentifier(name)
+	decoder.missing(name, identifier)
+	return identifier
+}
+
+func (decoder *jsonDecoder) optionalIdentifier(name string) *Identifier {
+	node := decoder.node(name)
+	if node == nil {
+		return nil
+	}
+
+	identifier, ok := node.(*Identifier)
+	if !ok {
+		decoder.fail("expected %q to be Identifier, got %s", name, nodeKind(node))
+	}
+
+	return identifier
+}
+
+func (decoder *jsonDecoder) function(name string) *FunctionLiteral {
+	node := decoder.node(name)
+	if decoder.missing(name, node) {
+		return nil
+	}
+
+	function, ok := node.(*FunctionLiteral)
+	if !ok {
+		decoder.fail("expected %q to be FunctionLiteral, got %s", name, nodeKind(node))
+	}
+
+	return function
+}
+
+func (decoder *jsonDecoder) block(name string) *BlockStatement {
+	block := decoder.optionalBlock(name)
+	decoder.missing(name, block)
+	return block
+}
+
+func (decoder *jsonDecoder) optionalBlock(name string) *BlockStatement {
+	node := decoder.node(name)
+	if node == nil {
+		return nil
+	}
+
+	block, ok := node.(*BlockStatement)
+	if !ok {
+		decoder.fail("expected %q to be BlockStatement, got %s", name, nodeKind(node))
+	}
+
+	return block
+}
@@ -0,0 +1,135 @@
+package ast_test
+
+import (
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/parser"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	inputs := []string{
+		"",
+		"let x = 5;",
+		"return -x;",
+		"(a + b) * c != !d;",
+		"if (x < y) { x } else { y }",
+		"if (true) { let a = 1; }",
+		"let add = fn(a, b) { return a + b; }; add(1, add(2, 3));",
+		"fn() {}()",
+		"let f = fn(x) { x == false };",
+		"// leading\nlet a = 1; // trailing",
+		"let f = fn(a, b = a * 2, ...rest) { [a, b, rest][1] };",
+		"fn even(n) { if (n == 0) { true } else { odd(n - 1) } }",
+		`let h = {"a\n": null, 1: [true]}; h?.a.b?["c"] ?? h[1];`,
+		"a ? b : c ? d : e;",
+		`match (x) { 1 => "one", -2 => null, _ => { x } }`,
+		`match (x) { [a, [_], ...rest] => rest, {"b": [b], c} => b + c, [] => 0 }`,
+		"match (x) {}",
+		`import "lib/a.mk" as a; export let [b] = a.b; export fn c() { b }`,
+		`let [a, _, ...rest] = x; let {name, "age": [age]} = y;`,
+		"fn f(_, {c}, [a, b] = [1, 2]) { a }",
+		"let f = 1.5 * -2.0;",
+		"match (x) { 1.5 => 1, -2.5 => 2 }",
+		"try { f() } catch (e) { e } finally { g() }; try { 1 } finally { 2 }",
+		`throw "x";`,
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			errors, program := parser.New(lexer.New(input)).ParseProgram()
+			assert.Nil(t, errors)
+
+			data, err := ast.EncodeJSON(program)
+			assert.Nil(t, err)
+
+			decoded, err := ast.DecodeProgramJSON(data)
+			assert.Nil(t, err)
+			assert.Equal(t, program, decoded)
+		})
+	}
+}
+
+func TestEncodeJSON(t *testing.T) {
+	_, program := parser.New(lexer.New("-x")).ParseProgram()
+
+	expected := `{"kind":"Program","statements":[` +
+		`{"kind":"ExpressionStatement","pos":{"line":1,"column":1},"token":{"type":"-","literal":"-"},"value":` +
+		`{"kind":"PrefixExpression","operator":"-","pos":{"line":1,"column":1},"right":` +
+		`{"kind":"Identifier","pos":{"line":1,"column":2},"value":"x"}}}]}`
+
+	data, err := ast.EncodeJSON(program)
+
+	assert.Nil(t, err)
+	assert.Equal(t, expected, string(data))
+}
+
+func TestDecodeJSONErrors(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{
+			`{"kind":"Nope"}`,
+			`decoding Nope: unknown kind "Nope"`,
+		},
+		{
+			`{"kind":"Identifier","value":"x"}`,
+			`decoding Identifier: missing field "pos"`,
+		},
+		{
+			`{"kind":"Program","statements":[{"kind":"Identifier","pos":{"line":1,"column":1},"value":"x"}]}`,
+			`decoding Program: expected statement in "statements", got Identifier`,
+		},
+		{
+			`{"kind":"Identifier","pos":{"line":1,"column":1},"value":"x"}`,
+			`expected Program, got Identifier`,
+		},
+		{
+			`{"kind":"ExportStatement","pos":{"line":1,"column":1},"statement":{"kind":"ReturnStatement","pos":{"line":1,"column":8},"value":{"kind":"NullLiteral","pos":{"line":1,"column":15}}}}`,
+			`decoding ExportStatement: expected "statement" to be LetStatement or FunctionStatement, got ReturnStatement`,
+		},
+		{
+			`{"kind":"ArrayPattern","pos":{"line":1,"column":1},"elements":[{"kind":"IntegerLiteral","pos":{"line":1,"column":2},"value":1}],"rest":null}`,
+			`decoding ArrayPattern: expected pattern, got IntegerLiteral`,
+		},
+		{
+			`{"kind":"Program","statements":[{"kind":"LetStatement","pos":{"line":1,"column":1},"name":null,"value":{"kind":"IntegerLiteral","pos":{"line":1,"column":1},"value":1}}]}`,
+			`decoding Program: field "statements": decoding LetStatement: missing "name"`,
+		},
+		{
+			`{"kind":"Program","statements":[{"kind":"LetStatement","pos":{"line":1,"column":1},"name":{"kind":"Identifier","pos":{"line":1,"column":1},"value":"x"},"value":null}]}`,
+			`decoding Program: field "statements": decoding LetStatement: missing "value"`,
+		},
+		{
+			`{"kind":"Program","statements":[{"kind":"FunctionStatement","pos":{"line":1,"column":1},"name":null,"function":null}]}`,
+			`decoding Program: field "statements": decoding FunctionStatement: missing "name"`,
+		},
+		{
+			`{"kind":"Program","statements":[{"kind":"ImportStatement","pos":{"line":1,"column":1},"path":{"kind":"StringLiteral","pos":{"line":1,"column":1},"value":"a.mk"},"alias":null}]}`,
+			`decoding Program: field "statements": decoding ImportStatement: missing "alias"`,
+		},
+		{
+			`{"kind":"Parameter","pos":{"line":1,"column":1},"rest":false,"name":null,"default":null}`,
+			`decoding Parameter: missing "name"`,
+		},
+		{
+			`{"kind":"ArrayLiteral","pos":{"line":1,"column":1},"elements":[null]}`,
+			`decoding ArrayLiteral: expected expression, got null`,
+		},
+		{
+			`{"kind":"TryExpression","pos":{"line":1,"column":1},"block":null,"parameter":null,"catch":null,"finally":{"kind":"BlockStatement","pos":{"line":1,"column":1},"statements":[],"end":{"line":1,"column":2}}}`,
+			`decoding TryExpression: missing "block"`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			_, err := ast.DecodeProgramJSON([]byte(testCase.input))
+
+			assert.EqualError(t, err, testCase.expected)
+		})
+	}
+}
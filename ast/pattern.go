@@ -0,0 +1,117 @@
+package ast
+
+import (
+	"monkey/token"
+	"strings"
+)
+
+// A Pattern describes the shape of a value and binds names to its parts.
+type Pattern interface {
+	Node
+	patternNode()
+}
+
+// An Identifier used as pattern matches any value and binds it to its name.
+func (identifier *Identifier) patternNode() {}
+
+// PatternNames returns the identifiers pattern binds, in source order.
+func PatternNames(pattern Pattern) []*Identifier {
+	names := []*Identifier{}
+
+	if isNilNode(pattern) {
+		return names
+	}
+
+	// Patterns never refer to variables, every identifier in them is a
+	// binding
+	Inspect(pattern, func(node Node) bool {
+		if identifier, ok := node.(*Identifier); ok {
+			names = append(names, identifier)
+		}
+		return true
+	})
+
+	return names
+}
+
+// A WildcardPattern matches any value without binding it.
+type WildcardPattern struct {
+	Token token.Token // the _ token
+}
+
+func (wildcardPattern *WildcardPattern) patternNode() {}
+func (wildcardPattern *WildcardPattern) TokenLiteral() string {
+	return wildcardPattern.Token.Literal
+}
+func (wildcardPattern *WildcardPattern) String() string {
+	return "_"
+}
+
+// A LiteralPattern matches values equal to a literal.
+type LiteralPattern struct {
+	Token token.Token // the first token of the literal
+	Value Expression  // an integer, float, string, boolean or null literal, or a negated number literal
+}
+
+func (literalPattern *LiteralPattern) patternNode() {}
+func (literalPattern *LiteralPattern) TokenLiteral() string {
+	return literalPattern.Token.Literal
+}
+func (literalPattern *LiteralPattern) String() string {
+	return literalPattern.Value.String()
+}
+
+// An ArrayPattern matches arrays element by element. Without a rest pattern
+// the array must have exactly as many elements as the pattern.
+type ArrayPattern struct {
+	Token    token.Token // the [ token
+	Elements []Pattern
+	Rest     Pattern // matches an array of the remaining elements, may be nil
+}
+
+func (arrayPattern *ArrayPattern) patternNode() {}
+func (arrayPattern *ArrayPattern) TokenLiteral() string {
+	return arrayPattern.Token.Literal
+}
+func (arrayPattern *ArrayPattern) String() string {
+	elements := []string{}
+	for _, element := range arrayPattern.Elements {
+		elements = append(elements, element.String())
+	}
+	if arrayPattern.Rest != nil {
+		elements = append(elements, "..."+arrayPattern.Rest.String())
+	}
+
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// A HashPattern matches hashes which contain all of its keys.
+type HashPattern struct {
+	Token token.Token // the { token
+	Pairs []HashPatternPair
+}
+
+// A HashPatternPair matches the value of Key. Shorthand pairs are written as
+// a name only, e.g. {name}, which matches the key "name" and binds it to name.
+type HashPatternPair struct {
+	Key       Expression // an integer, string or boolean literal
+	Value     Pattern
+	Shorthand bool
+}
+
+func (hashPattern *HashPattern) patternNode() {}
+func (hashPattern *HashPattern) TokenLiteral() string {
+	return hashPattern.Token.Literal
+}
+func (hashPattern *HashPattern) String() string {
+	pairs := []string{}
+	for _, pair := range hashPattern.Pairs {
+		if pair.Shorthand {
+			pairs = append(pairs, pair.Value.String())
+		} else {
+			pairs = append(pairs, pair.Key.String()+": "+pair.Value.String())
+		}
+	}
+
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
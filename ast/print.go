@@ -0,0 +1,78 @@
+package ast
+
+import (
+	"fmt"
+	"io"
+	"monkey/token"
+	"reflect"
+	"strings"
+)
+
+var tokenType = reflect.TypeOf(token.Token{})
+
+// Fprint writes node as an indented tree to writer. Every node is printed
+// with its type name and source position, followed by its fields.
+func Fprint(writer io.Writer, node Node) error {
+	printer := &treePrinter{writer: writer}
+	printer.printValue("", reflect.ValueOf(node), 0)
+	return printer.err
+}
+
+type treePrinter struct {
+	writer io.Writer
+	err    error
+}
+
+func (printer *treePrinter) printf(depth int, format string, a ...any) {
+	if printer.err != nil {
+		return
+	}
+	indent := strings.Repeat("  ", depth)
+	_, printer.err = fmt.Fprintf(printer.writer, indent+format+"\n", a...)
+}
+
+func (printer *treePrinter) printValue(label string, value reflect.Value, depth int) {
+	switch value.Kind() {
+	case reflect.Invalid:
+		printer.printf(depth, "%snil", label)
+
+	case reflect.Interface, reflect.Pointer:
+		if value.IsNil() {
+			printer.printf(depth, "%snil", label)
+			return
+		}
+		printer.printValue(label, value.Elem(), depth)
+
+	case reflect.Slice:
+		printer.printf(depth, "%s[%d]", label, value.Len())
+		for index := 0; index < value.Len(); index++ {
+			printer.printValue(fmt.Sprintf("%d: ", index), value.Index(index), depth+1)
+		}
+
+	case reflect.Struct:
+		printer.printStruct(label, value, depth)
+
+	default:
+		printer.printf(depth, "%s%#v", label, value.Interface())
+	}
+}
+
+func (printer *treePrinter) printStruct(label string, value reflect.Value, depth int) {
+	header := label + value.Type().Name()
+
+	if tok := value.FieldByName("Token"); tok.IsValid() && tok.Type() == tokenType {
+		header += " " + tok.Interface().(token.Token).Position.String()
+	}
+
+	printer.printf(depth, "%s", header)
+
+	for index := 0; index < value.NumField(); index++ {
+		field := value.Type().Field(index)
+
+		if field.Type == tokenType || !field.IsExported() {
+			continue
+		}
+
+		printer.printValue(field.Name+": ", value.Field(index), depth+1)
+	}
+}
@@ -0,0 +1,74 @@
+package ast
+
+import (
+	"bytes"
+	"monkey/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFprint(t *testing.T) {
+	program := &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Token: token.Token{
+					Type:     token.LET,
+					Literal:  "let",
+					Position: token.Position{Line: 1, Column: 1},
+				},
+				Name: &Identifier{
+					Token: token.Token{
+						Type:     token.IDENT,
+						Literal:  "x",
+						Position: token.Position{Line: 1, Column: 5},
+					},
+					Value: "x",
+				},
+				Value: &IfExpression{
+					Token: token.Token{
+						Type:     token.IF,
+						Literal:  "if",
+						Position: token.Position{Line: 1, Column: 9},
+					},
+					Condition: &Boolean{
+						Token: token.Token{
+							Type:     token.TRUE,
+							Literal:  "true",
+							Position: token.Position{Line: 1, Column: 13},
+						},
+						Value: true,
+					},
+					Consequence: &BlockStatement{
+						Token: token.Token{
+							Type:     token.LBRACE,
+							Literal:  "{",
+							Position: token.Position{Line: 1, Column: 19},
+						},
+						Statements: []Statement{},
+					},
+				},
+			},
+		},
+	}
+
+	expected := `Program
+  Statements: [1]
+    0: LetStatement 1:1
+      Name: Identifier 1:5
+        Value: "x"
+      Value: IfExpression 1:9
+        Condition: Boolean 1:13
+          Value: true
+        Consequence: BlockStatement 1:19
+          Statements: [0]
+        Alternative: nil
+  Comments: [0]
+`
+
+	var out bytes.Buffer
+	err := Fprint(&out, program)
+
+	assert.Nil(t, err)
+	assert.Equal(t, expected, out.String())
+}
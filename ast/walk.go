@@ -0,0 +1,331 @@
+package ast
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.
+// If the returned visitor is not nil, Walk visits each of the children of
+// node with it, followed by a call of Visit(nil).
+type Visitor interface {
+	Visit(node Node) Visitor
+}
+
+// Walk traverses the syntax tree in depth-first order. It starts by calling
+// visitor.Visit(node); node must not be nil.
+func Walk(visitor Visitor, node Node) {
+	if visitor = visitor.Visit(node); visitor == nil {
+		return
+	}
+
+	for _, child := range children(node) {
+		Walk(visitor, child)
+	}
+
+	visitor.Visit(nil)
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses the syntax tree in depth-first order. It starts by
+// calling f(node); if f returns true, Inspect is called recursively for each
+// of the non-nil children of node, followed by a call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// children returns the non-nil child nodes of node in source order.
+func children(node Node) []Node {
+	nodes := []Node{}
+
+	add := func(children ...Node) {
+		for _, child := range children {
+			if !isNilNode(child) {
+				nodes = append(nodes, child)
+			}
+		}
+	}
+
+	switch node := node.(type) {
+	case *Program:
+		for _, statement := range node.Statements {
+			add(statement)
+		}
+
+	case *LetStatement:
+		add(node.Name, node.Value)
+
+	case *FunctionStatement:
+		add(node.Name, node.Function)
+
+	case *ImportStatement:
+		add(node.Path, node.Alias)
+
+	case *ExportStatement:
+		add(node.Statement)
+
+	case *ReturnStatement:
+		add(node.Value)
+
+	case *ThrowStatement:
+		add(node.Value)
+
+	case *ExpressionStatement:
+		add(node.Value)
+
+	case *BlockStatement:
+		for _, statement := range node.Statements {
+			add(statement)
+		}
+
+	case *IfExpression:
+		add(node.Condition, node.Consequence, node.Alternative)
+
+	case *TryExpression:
+		add(node.Block, node.Parameter, node.Catch, node.Finally)
+
+	case *ConditionalExpression:
+		add(node.Condition, node.Consequence, node.Alternative)
+
+	case *MatchExpression:
+		add(node.Subject)
+		for _, arm := range node.Arms {
+			add(arm)
+		}
+
+	case *MatchArm:
+		add(node.Pattern, node.Body)
+
+	case *LiteralPattern:
+		add(node.Value)
+
+	case *ArrayPattern:
+		for _, element := range node.Elements {
+			add(element)
+		}
+		add(node.Rest)
+
+	case *HashPattern:
+		for _, pair := range node.Pairs {
+			add(pair.Key, pair.Value)
+		}
+
+	case *FunctionLiteral:
+		for _, parameter := range node.Parameters {
+			add(parameter)
+		}
+		add(node.Body)
+
+	case *Parameter:
+		add(node.Name, node.Default)
+
+	case *CallExpression:
+		add(node.Function)
+		for _, argument := range node.Arguments {
+			add(argument)
+		}
+
+	case *ArrayLiteral:
+		for _, element := range node.Elements {
+			add(element)
+		}
+
+	case *IndexExpression:
+		add(node.Left, node.Index)
+
+	case *MemberExpression:
+		add(node.Object, node.Property)
+
+	case *HashLiteral:
+		for _, pair := range node.Pairs {
+			add(pair.Key, pair.Value)
+		}
+
+	case *PrefixExpression:
+		add(node.Right)
+
+	case *InfixExpression:
+		add(node.Left, node.Right)
+	}
+
+	return nodes
+}
+
+// Rewrite traverses the syntax tree in depth-first order and replaces every
+// node with the result of f. The children of a node are rewritten before f
+// is called for the node itself, so f always sees the rewritten children.
+//
+// Replacements must fit the place of the original node, e.g. an expression
+// can only be replaced by another expression. Returning nil for a statement
+// removes it from its program or block. Rewrite returns the new root node.
+func Rewrite(node Node, f func(Node) Node) Node {
+	if isNilNode(node) {
+		return node
+	}
+
+	switch node := node.(type) {
+	case *Program:
+		node.Statements = rewriteStatements(node.Statements, f)
+
+	case *LetStatement:
+		node.Name = rewritePattern(node.Name, f)
+		node.Value = rewriteExpression(node.Value, f)
+
+	case *FunctionStatement:
+		node.Name = rewriteIdentifier(node.Name, f)
+		if node.Function != nil {
+			node.Function = Rewrite(node.Function, f).(*FunctionLiteral)
+		}
+
+	case *ImportStatement:
+		if node.Path != nil {
+			node.Path = Rewrite(node.Path, f).(*StringLiteral)
+		}
+		node.Alias = rewriteIdentifier(node.Alias, f)
+
+	case *ExportStatement:
+		if !isNilNode(node.Statement) {
+			node.Statement = Rewrite(node.Statement, f).(Statement)
+		}
+
+	case *ReturnStatement:
+		node.Value = rewriteExpression(node.Value, f)
+
+	case *ThrowStatement:
+		node.Value = rewriteExpression(node.Value, f)
+
+	case *ExpressionStatement:
+		node.Value = rewriteExpression(node.Value, f)
+
+	case *BlockStatement:
+		node.Statements = rewriteStatements(node.Statements, f)
+
+	case *IfExpression:
+		node.Condition = rewriteExpression(node.Condition, f)
+		node.Consequence = rewriteBlock(node.Consequence, f)
+		node.Alternative = rewriteBlock(node.Alternative, f)
+
+	case *TryExpression:
+		node.Block = rewriteBlock(node.Block, f)
+		node.Parameter = rewriteIdentifier(node.Parameter, f)
+		node.Catch = rewriteBlock(node.Catch, f)
+		node.Finally = rewriteBlock(node.Finally, f)
+
+	case *ConditionalExpression:
+		node.Condition = rewriteExpression(node.Condition, f)
+		node.Consequence = rewriteExpression(node.Consequence, f)
+		node.Alternative = rewriteExpression(node.Alternative, f)
+
+	case *MatchExpression:
+		node.Subject = rewriteExpression(node.Subject, f)
+		for index, arm := range node.Arms {
+			node.Arms[index] = Rewrite(arm, f).(*MatchArm)
+		}
+
+	case *MatchArm:
+		node.Pattern = rewritePattern(node.Pattern, f)
+		node.Body = Rewrite(node.Body, f)
+
+	case *LiteralPattern:
+		node.Value = rewriteExpression(node.Value, f)
+
+	case *ArrayPattern:
+		for index, element := range node.Elements {
+			node.Elements[index] = rewritePattern(element, f)
+		}
+		node.Rest = rewritePattern(node.Rest, f)
+
+	case *HashPattern:
+		for index, pair := range node.Pairs {
+			node.Pairs[index].Key = rewriteExpression(pair.Key, f)
+			node.Pairs[index].Value = rewritePattern(pair.Value, f)
+		}
+
+	case *FunctionLiteral:
+		for index, parameter := range node.Parameters {
+			node.Parameters[index] = Rewrite(parameter, f).(*Parameter)
+		}
+		node.Body = rewriteBlock(node.Body, f)
+
+	case *Parameter:
+		node.Name = rewritePattern(node.Name, f)
+		node.Default = rewriteExpression(node.Default, f)
+
+	case *CallExpression:
+		node.Function = rewriteExpression(node.Function, f)
+		for index, argument := range node.Arguments {
+			node.Arguments[index] = rewriteExpression(argument, f)
+		}
+
+	case *ArrayLiteral:
+		for index, element := range node.Elements {
+			node.Elements[index] = rewriteExpression(element, f)
+		}
+
+	case *IndexExpression:
+		node.Left = rewriteExpression(node.Left, f)
+		node.Index = rewriteExpression(node.Index, f)
+
+	case *MemberExpression:
+		node.Object = rewriteExpression(node.Object, f)
+		node.Property = rewriteIdentifier(node.Property, f)
+
+	case *HashLiteral:
+		for index, pair := range node.Pairs {
+			node.Pairs[index].Key = rewriteExpression(pair.Key, f)
+			node.Pairs[index].Value = rewriteExpression(pair.Value, f)
+		}
+
+	case *PrefixExpression:
+		node.Right = rewriteExpression(node.Right, f)
+
+	case *InfixExpression:
+		node.Left = rewriteExpression(node.Left, f)
+		node.Right = rewriteExpression(node.Right, f)
+	}
+
+	return f(node)
+}
+
+func rewriteStatements(statements []Statement, f func(Node) Node) []Statement {
+	rewritten := statements[:0]
+
+	for _, statement := range statements {
+		if result := Rewrite(statement, f); !isNilNode(result) {
+			rewritten = append(rewritten, result.(Statement))
+		}
+	}
+
+	return rewritten
+}
+
+func rewriteExpression(expression Expression, f func(Node) Node) Expression {
+	if isNilNode(expression) {
+		return expression
+	}
+	return Rewrite(expression, f).(Expression)
+}
+
+func rewritePattern(pattern Pattern, f func(Node) Node) Pattern {
+	if isNilNode(pattern) {
+		return pattern
+	}
+	return Rewrite(pattern, f).(Pattern)
+}
+
+func rewriteIdentifier(identifier *Identifier, f func(Node) Node) *Identifier {
+	if identifier == nil {
+		return nil
+	}
+	return Rewrite(identifier, f).(*Identifier)
+}
+
+func rewriteBlock(block *BlockStatement, f func(Node) Node) *BlockStatement {
+	if block == nil {
+		return nil
+	}
+	return Rewrite(block, f).(*BlockStatement)
+}
@@ -0,0 +1,185 @@
+package ast_test
+
+import (
+	"fmt"
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/parser"
+	"monkey/token"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	errors, program := parser.New(lexer.New(input)).ParseProgram()
+	assert.Nil(t, errors)
+	return program
+}
+
+func TestInspect(t *testing.T) {
+	program := parse(t, `
+	let add = fn(a, b) { return a + b; };
+	if (add(1, -2) > 0) { true } else { false };
+	`)
+
+	visited := []string{}
+	ast.Inspect(program, func(node ast.Node) bool {
+		if node != nil {
+			visited = append(visited, fmt.Sprintf("%T", node))
+		}
+		return true
+	})
+
+	expected := []string{
+		"*ast.Program",
+		"*ast.LetStatement",
+		"*ast.Identifier",
+		"*ast.FunctionLiteral",
+		"*ast.Parameter",
+		"*ast.Identifier",
+		"*ast.Parameter",
+		"*ast.Identifier",
+		"*ast.BlockStatement",
+		"*ast.ReturnStatement",
+		"*ast.InfixExpression",
+		"*ast.Identifier",
+		"*ast.Identifier",
+		"*ast.ExpressionStatement",
+		"*ast.IfExpression",
+		"*ast.InfixExpression",
+		"*ast.CallExpression",
+		"*ast.Identifier",
+		"*ast.IntegerLiteral",
+		"*ast.PrefixExpression",
+		"*ast.IntegerLiteral",
+		"*ast.IntegerLiteral",
+		"*ast.BlockStatement",
+		"*ast.ExpressionStatement",
+		"*ast.Boolean",
+		"*ast.BlockStatement",
+		"*ast.ExpressionStatement",
+		"*ast.Boolean",
+	}
+
+	assert.Equal(t, expected, visited)
+}
+
+func TestInspectSkipsChildren(t *testing.T) {
+	program := parse(t, "let f = fn(x) { y }; z;")
+
+	identifiers := []string{}
+	ast.Inspect(program, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FunctionLiteral:
+			return false
+		case *ast.Identifier:
+			identifiers = append(identifiers, node.Value)
+		}
+		return true
+	})
+
+	assert.Equal(t, []string{"f", "z"}, identifiers)
+}
+
+type depthVisitor struct {
+	depth int
+	out   *strings.Builder
+}
+
+func (visitor depthVisitor) Visit(node ast.Node) ast.Visitor {
+	if node == nil {
+		return nil
+	}
+	fmt.Fprintf(visitor.out, "%s%s\n", strings.Repeat(" ", visitor.depth), node.TokenLiteral())
+	return depthVisitor{depth: visitor.depth + 1, out: visitor.out}
+}
+
+func TestWalk(t *testing.T) {
+	program := parse(t, "if (a) { b(c) }")
+
+	var out strings.Builder
+	ast.Walk(depthVisitor{out: &out}, program)
+
+	expected := `if
+ if
+  if
+   a
+   {
+    b
+     (
+      b
+      c
+`
+
+	assert.Equal(t, expected, out.String())
+}
+
+func TestRewrite(t *testing.T) {
+	program := parse(t, `
+	let x = 1 + 2;
+	fn(a) { if (a) { 3 } else { 4 } }(5 + 6);
+	`)
+
+	rewritten := ast.Rewrite(program, func(node ast.Node) ast.Node {
+		infix, ok := node.(*ast.InfixExpression)
+		if !ok || infix.Operator != "+" {
+			return node
+		}
+
+		left, leftOk := infix.Left.(*ast.IntegerLiteral)
+		right, rightOk := infix.Right.(*ast.IntegerLiteral)
+		if !leftOk || !rightOk {
+			return node
+		}
+
+		value := left.Value + right.Value
+		return &ast.IntegerLiteral{
+			Token: token.Token{Type: token.INT, Literal: fmt.Sprint(value)},
+			Value: value,
+		}
+	})
+
+	assert.Equal(t, "let x = 3;fn(a) { if a { 3 } else { 4 } }(11)", rewritten.String())
+
+	rewritten = ast.Rewrite(program, func(node ast.Node) ast.Node {
+		if integer, ok := node.(*ast.IntegerLiteral); ok {
+			integer.Value *= 10
+			integer.Token.Literal = fmt.Sprint(integer.Value)
+		}
+		return node
+	})
+
+	assert.Equal(t, "let x = 30;fn(a) { if a { 30 } else { 40 } }(110)", rewritten.String())
+}
+
+func TestRewritePatterns(t *testing.T) {
+	program := parse(t, "match (x) { [1, ...r] => r, {2: y} => y ? 3 : 4 }")
+
+	rewritten := ast.Rewrite(program, func(node ast.Node) ast.Node {
+		if identifier, ok := node.(*ast.Identifier); ok {
+			identifier.Value = strings.ToUpper(identifier.Value)
+		}
+		if integer, ok := node.(*ast.IntegerLiteral); ok {
+			integer.Value *= 10
+			integer.Token.Literal = fmt.Sprint(integer.Value)
+		}
+		return node
+	})
+
+	assert.Equal(t, "match (X) { [10, ...R] => R, {20: Y} => (Y ? 30 : 40) }", rewritten.String())
+}
+
+func TestRewriteRemovesStatements(t *testing.T) {
+	program := parse(t, "let a = 1; if (a) { let b = 2; b; }; a;")
+
+	rewritten := ast.Rewrite(program, func(node ast.Node) ast.Node {
+		if _, ok := node.(*ast.LetStatement); ok {
+			return nil
+		}
+		return node
+	})
+
+	assert.Equal(t, "if a { b }a", rewritten.String())
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/parser"
+)
+
+func runAst(flags *flag.FlagSet, args []string, env *environment) int {
+	asJson := flags.Bool("json", false, "print the syntax tree as JSON")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	source, err := readSource(flags, env)
+	if err != nil {
+		fmt.Fprintln(env.stderr, err)
+		return 1
+	}
+
+	errors, program := parser.New(lexer.New(source)).ParseProgram()
+
+	if *asJson {
+		data, err := ast.EncodeJSON(program)
+		if err != nil {
+			fmt.Fprintln(env.stderr, err)
+			return 1
+		}
+
+		var output bytes.Buffer
+		json.Indent(&output, data, "", "  ")
+		fmt.Fprintf(env.stdout, "%s\n", output.String())
+	} else {
+		ast.Fprint(env.stdout, program)
+	}
+
+	// The (partial) tree is printed anyway, as it helps to track down
+	// where the parser went off.
+	if errors != nil {
+		outputErrors(env.stderr, errors)
+		return 1
+	}
+
+	return 0
+}
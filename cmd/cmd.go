@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+type command struct {
+	usage string
+	run   func(flags *flag.FlagSet, args []string, env *environment) int
+}
+
+type environment struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+var commands = map[string]command{
+	"tokens": {
+		usage: "tokens [file]\n\tprint the token stream of a script",
+		run:   runTokens,
+	},
+	"ast": {
+		usage: "ast [-json] [file]\n\tprint the syntax tree of a script",
+		run:   runAst,
+	},
+	"fmt": {
+		usage: "fmt [-w] [-d] [files...]\n\tformat scripts in the canonical style",
+		run:   runFmt,
+	},
+	"run": {
+		usage: "run [-path dirs] [-seed n] [-allow-read paths] [-allow-write paths] [-allow-env names] [file [args...]]\n\tevaluate a script and print its result",
+		run:   runRun,
+	},
+	"lint": {
+		usage: "lint [-enable rules] [-disable rules] [-list] [files...]\n\treport suspicious code in scripts",
+		run:   runLint,
+	},
+}
+
+// Run executes the subcommand named by the first argument and returns the
+// exit code for the process. Scripts are read from the files given as
+// arguments, or from stdin if there are none.
+func Run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	env := &environment{stdin: stdin, stdout: stdout, stderr: stderr}
+
+	if len(args) == 0 {
+		printUsage(stderr)
+		return 2
+	}
+
+	command, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n", args[0])
+		printUsage(stderr)
+		return 2
+	}
+
+	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: monkey %s\n", command.usage)
+		flags.PrintDefaults()
+	}
+
+	return command.run(flags, args[1:], env)
+}
+
+func printUsage(out io.Writer) {
+	names := []string{}
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(out, "usage: monkey <command> [arguments]\n\ncommands:\n")
+	for _, name := range names {
+		fmt.Fprintf(out, "  %s\n", commands[name].usage)
+	}
+}
+
+func readSource(flags *flag.FlagSet, env *environment) (string, error) {
+	var source []byte
+	var err error
+
+	switch path := flags.Arg(0); path {
+	case "", "-":
+		source, err = io.ReadAll(env.stdin)
+	default:
+		source, err = os.ReadFile(path)
+	}
+
+	return string(source), err
+}
+
+func outputErrors(out io.Writer, errors []string) {
+	for _, error := range errors {
+		fmt.Fprintf(out, "%s\n", error)
+	}
+}
@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func runCommand(args []string, input string) (code int, stdout string, stderr string) {
+	var out, errOut bytes.Buffer
+	code = Run(args, strings.NewReader(input), &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+func TestTokens(t *testing.T) {
+	code, stdout, stderr := runCommand([]string{"tokens"}, "let x = 5;")
+
+	expected := `1:1      LET        "let"
+1:5      IDENT      "x"
+1:7      =          "="
+1:9      INT        "5"
+1:10     ;          ";"
+1:11     EOF        ""
+`
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, expected, stdout)
+	assert.Empty(t, stderr)
+}
+
+func TestAst(t *testing.T) {
+	code, stdout, stderr := runCommand([]string{"ast"}, "a + 1")
+
+	expected := `Program
+  Statements: [1]
+    0: ExpressionStatement 1:1
+      Value: InfixExpression 1:3
+        Operator: "+"
+        Left: Identifier 1:1
+          Value: "a"
+        Right: IntegerLiteral 1:5
+          Value: 1
+  Comments: [0]
+`
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, expected, stdout)
+	assert.Empty(t, stderr)
+}
+
+func TestAstParserErrors(t *testing.T) {
+	code, _, stderr := runCommand([]string{"ast"}, "let = 5;")
+
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "expected next token to be IDENT, got = instead\n", stderr)
+}
+
+func TestUnknownCommand(t *testing.T) {
+	code, _, stderr := runCommand([]string{"nope"}, "")
+
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown command "nope"`)
+}
+
+func TestAstJSON(t *testing.T) {
+	code, stdout, _ := runCommand([]string{"ast", "-json"}, "x")
+
+	expected := `{
+  "kind": "Program",
+  "statements": [
+    {
+      "kind": "ExpressionStatement",
+      "pos": {
+        "line": 1,
+        "column": 1
+      },
+      "token": {
+        "type": "IDENT",
+        "literal": "x"
+      },
+      "value": {
+        "kind": "Identifier",
+        "pos": {
+          "line": 1,
+          "column": 1
+        },
+        "value": "x"
+      }
+    }
+  ]
+}
+`
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, expected, stdout)
+}
+
+func TestFmt(t *testing.T) {
+	code, stdout, stderr := runCommand([]string{"fmt"}, "let a=fn(x){x}")
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "let a = fn(x) {\n\tx;\n};\n", stdout)
+	assert.Empty(t, stderr)
+}
+
+func TestFmtParserErrors(t *testing.T) {
+	code, stdout, stderr := runCommand([]string{"fmt"}, "let = 1")
+
+	assert.Equal(t, 1, code)
+	assert.Empty(t, stdout)
+	assert.Equal(t, "<stdin>: expected next token to be IDENT, got = instead\n", stderr)
+}
+
+func TestFmtWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.mk")
+	os.WriteFile(path, []byte("let a=1"), 0644)
+
+	code, stdout, _ := runCommand([]string{"fmt", "-w", path}, "")
+	content, _ := os.ReadFile(path)
+
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stdout)
+	assert.Equal(t, "let a = 1;\n", string(content))
+
+	code, _, stderr := runCommand([]string{"fmt", "-w"}, "let a=1")
+
+	assert.Equal(t, 2, code)
+	assert.Equal(t, "cannot use -w with stdin\n", stderr)
+}
+
+func TestFmtDiff(t *testing.T) {
+	code, stdout, _ := runCommand([]string{"fmt", "-d"}, "let a = 1;\nlet b=2\nlet c = 3;\n")
+
+	expected := `--- <stdin>.orig
++++ <stdin>
+@@ -1,3 +1,3 @@
+ let a = 1;
+-let b=2
++let b = 2;
+ let c = 3;
+`
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, expected, stdout)
+
+	code, stdout, _ = runCommand([]string{"fmt", "-d"}, "let a = 1;\n")
+
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stdout)
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	before := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n"
+	after := "0\n1\n2\n3\n4\n5\n6\n7\n8\nnine\n10\n11\n12\n"
+
+	expected := `--- a.orig
++++ a
+@@ -1,3 +1,4 @@
++0
+ 1
+ 2
+ 3
+@@ -6,8 +7,7 @@
+ 6
+ 7
+ 8
+-9
++nine
+ 10
+ 11
+ 12
+-13
+`
+
+	assert.Equal(t, expected, unifiedDiff("a", before, after))
+}
+
+func TestLint(t *testing.T) {
+	input := "let a = 1;\nlet f = fn(x) { return 1; x };"
+
+	code, stdout, _ := runCommand([]string{"lint"}, input)
+
+	expected := `<stdin>:1:5: a declared but never used (unused-let)
+<stdin>:2:5: f declared but never used (unused-let)
+<stdin>:2:27: unreachable code (unreachable)
+`
+
+	assert.Equal(t, 1, code)
+	assert.Equal(t, expected, stdout)
+
+	code, stdout, _ = runCommand([]string{"lint", "-disable", "unused-let, unreachable"}, input)
+
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stdout)
+
+	code, stdout, _ = runCommand([]string{"lint", "-enable", "unreachable"}, input)
+
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "<stdin>:2:27: unreachable code (unreachable)\n", stdout)
+
+	code, _, stderr := runCommand([]string{"lint", "-enable", "nope"}, input)
+
+	assert.Equal(t, 2, code)
+	assert.Equal(t, "unknown rule \"nope\"\n", stderr)
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	lib := filepath.Join(dir, "lib")
+	assert.Nil(t, os.Mkdir(lib, 0o755))
+	assert.Nil(t, os.WriteFile(filepath.Join(lib, "math.mk"), []byte("export fn double(x) { x * 2 }"), 0o644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "main.mk"), []byte(`import "math.mk" as math; math.double(21)`), 0o644))
+
+	code, stdout, stderr := runCommand([]string{"run", "-path", lib, filepath.Join(dir, "main.mk")}, "")
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "42\n", stdout)
+	assert.Empty(t, stderr)
+
+	code, stdout, stderr = runCommand([]string{"run", filepath.Join(dir, "main.mk")}, "")
+
+	assert.Equal(t, 1, code)
+	assert.Empty(t, stdout)
+	assert.Equal(t, "Error: cannot find module \"math.mk\"\n", stderr)
+
+	code, stdout, _ = runCommand([]string{"run"}, "let a = 1;")
+
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stdout)
+
+	code, _, stderr = runCommand([]string{"run"}, "let = 1;")
+
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "expected next token to be IDENT, got = instead\n", stderr)
+
+	code, _, stderr = runCommand([]string{"run"}, `fn check(x) { len(x) } fn main() { check(1) } main()`)
+
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "Error: len: argument 1 must be STRING, ARRAY or HASH, got INTEGER\n\tin builtin len\n\tin fn check(x)\n\tin fn main()\n", stderr)
+}
+
+func TestRunSeed(t *testing.T) {
+	input := "map(range(5), fn(_) { math.random_int(1000) })"
+
+	_, first, _ := runCommand([]string{"run", "-seed", "42"}, input)
+	_, second, _ := runCommand([]string{"run", "-seed", "42"}, input)
+
+	assert.NotEmpty(t, first)
+	assert.Equal(t, first, second)
+}
+
+func TestRunErrorValues(t *testing.T) {
+	input := `let size = len(1); is_error(size) ? size.message : size`
+
+	code, stdout, _ := runCommand([]string{"run", "-error-values"}, input)
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "len: argument 1 must be STRING, ARRAY or HASH, got INTEGER\n", stdout)
+
+	code, _, _ = runCommand([]string{"run"}, input)
+
+	assert.Equal(t, 1, code)
+}
+
+func TestRunCapabilities(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "main.mk")
+	assert.Nil(t, os.WriteFile(script, []byte(`let [name] = args(); len(read_file(name)) > 0 ? exit(3) : 0`), 0o644))
+
+	code, stdout, stderr := runCommand([]string{"run", "-allow-read", dir, script, script}, "")
+
+	assert.Equal(t, 3, code)
+	assert.Empty(t, stdout)
+	assert.Empty(t, stderr)
+
+	code, _, stderr = runCommand([]string{"run", script, script}, "")
+
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "Error: identifier not found read_file\n", stderr)
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const diffContext = 3
+
+type diffLine struct {
+	kind     byte // ' ', '-' or '+'
+	text     string
+	oldIndex int // number of old lines before this line
+	newIndex int // number of new lines before this line
+}
+
+// unifiedDiff returns the changes between before and after in unified
+// format, or an empty string if there are none.
+func unifiedDiff(name string, before string, after string) string {
+	if before == after {
+		return ""
+	}
+
+	lines := diffLines(splitLines(before), splitLines(after))
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "--- %s.orig\n+++ %s\n", name, name)
+
+	for index := 0; index < len(lines); {
+		if lines[index].kind == ' ' {
+			index++
+			continue
+		}
+
+		start := max(0, index-diffContext)
+		lastChange := index
+
+		for next := index; next < len(lines) && next-lastChange <= 2*diffContext; next++ {
+			if lines[next].kind != ' ' {
+				lastChange = next
+			}
+		}
+
+		end := min(len(lines), lastChange+diffContext+1)
+		writeHunk(&out, lines[start:end])
+		index = end
+	}
+
+	return out.String()
+}
+
+func writeHunk(out *bytes.Buffer, hunk []diffLine) {
+	oldCount, newCount := 0, 0
+	for _, line := range hunk {
+		if line.kind != '+' {
+			oldCount++
+		}
+		if line.kind != '-' {
+			newCount++
+		}
+	}
+
+	oldStart, newStart := hunk[0].oldIndex, hunk[0].newIndex
+	if oldCount > 0 {
+		oldStart++
+	}
+	if newCount > 0 {
+		newStart++
+	}
+
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, line := range hunk {
+		fmt.Fprintf(out, "%c%s\n", line.kind, line.text)
+	}
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines aligns old and new lines along their longest common subsequence.
+func diffLines(old []string, new []string) []diffLine {
+	// common[i][j] holds the length of the longest common subsequence of
+	// old[i:] and new[j:]
+	common := make([][]int, len(old)+1)
+	for i := range common {
+		common[i] = make([]int, len(new)+1)
+	}
+
+	for i := len(old) - 1; i >= 0; i-- {
+		for j := len(new) - 1; j >= 0; j-- {
+			if old[i] == new[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	lines := []diffLine{}
+	i, j := 0, 0
+
+	for i < len(old) || j < len(new) {
+		switch {
+		case i < len(old) && j < len(new) && old[i] == new[j]:
+			lines = append(lines, diffLine{' ', old[i], i, j})
+			i++
+			j++
+		case j == len(new) || i < len(old) && common[i+1][j] >= common[i][j+1]:
+			lines = append(lines, diffLine{'-', old[i], i, j})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', new[j], i, j})
+			j++
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"monkey/format"
+	"os"
+)
+
+func runFmt(flags *flag.FlagSet, args []string, env *environment) int {
+	write := flags.Bool("w", false, "write the result to the file instead of stdout")
+	diff := flags.Bool("d", false, "print a diff instead of the formatted source")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		if *write {
+			fmt.Fprintln(env.stderr, "cannot use -w with stdin")
+			return 2
+		}
+		return formatFile("<stdin>", env.stdin, false, *diff, env)
+	}
+
+	code := 0
+
+	for _, path := range flags.Args() {
+		file, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintln(env.stderr, err)
+			code = 1
+			continue
+		}
+
+		if formatFile(path, file, *write, *diff, env) != 0 {
+			code = 1
+		}
+		file.Close()
+	}
+
+	return code
+}
+
+func formatFile(path string, in io.Reader, write bool, diff bool, env *environment) int {
+	source, err := io.ReadAll(in)
+	if err != nil {
+		fmt.Fprintln(env.stderr, err)
+		return 1
+	}
+
+	formatted, err := format.Source(string(source))
+	if err != nil {
+		fmt.Fprintf(env.stderr, "%s: %s\n", path, err)
+		return 1
+	}
+
+	if diff {
+		fmt.Fprint(env.stdout, unifiedDiff(path, string(source), formatted))
+	}
+
+	if write && formatted != string(source) {
+		if err := os.WriteFile(path, []byte(formatted), 0644); err != nil {
+			fmt.Fprintln(env.stderr, err)
+			return 1
+		}
+	}
+
+	if !diff && !write {
+		fmt.Fprint(env.stdout, formatted)
+	}
+
+	return 0
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"monkey/lexer"
+	"monkey/lint"
+	"monkey/parser"
+	"os"
+	"strings"
+)
+
+func runLint(flags *flag.FlagSet, args []string, env *environment) int {
+	enable := flags.String("enable", "", "comma separated list of rules to run instead of all rules")
+	disable := flags.String("disable", "", "comma separated list of rules to skip")
+	list := flags.Bool("list", false, "list all available rules")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *list {
+		for _, rule := range lint.Rules {
+			fmt.Fprintf(env.stdout, "%-20s %s\n", rule.Name, rule.Description)
+		}
+		return 0
+	}
+
+	rules, err := lint.Select(splitList(*enable), splitList(*disable))
+	if err != nil {
+		fmt.Fprintln(env.stderr, err)
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		return lintFile("<stdin>", env.stdin, rules, env)
+	}
+
+	code := 0
+
+	for _, path := range flags.Args() {
+		file, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintln(env.stderr, err)
+			code = 1
+			continue
+		}
+
+		if lintFile(path, file, rules, env) != 0 {
+			code = 1
+		}
+		file.Close()
+	}
+
+	return code
+}
+
+func lintFile(path string, in io.Reader, rules []*lint.Rule, env *environment) int {
+	source, err := io.ReadAll(in)
+	if err != nil {
+		fmt.Fprintln(env.stderr, err)
+		return 1
+	}
+
+	errors, program := parser.New(lexer.New(string(source))).ParseProgram()
+	if errors != nil {
+		for _, error := range errors {
+			fmt.Fprintf(env.stderr, "%s: %s\n", path, error)
+		}
+		return 1
+	}
+
+	diagnostics := lint.Lint(program, rules)
+	for _, diagnostic := range diagnostics {
+		fmt.Fprintf(env.stdout, "%s:%s\n", path, diagnostic)
+	}
+
+	if len(diagnostics) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func splitList(list string) []string {
+	names := []string{}
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"monkey/eval"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"path/filepath"
+)
+
+func runRun(flags *flag.FlagSet, args []string, env *environment) int {
+	path := flags.String("path", "", "list of directories to search for imports, separated by "+string(filepath.ListSeparator))
+	seed := flags.Int64("seed", 0, "seed of the random numbers, random if 0")
+	allowRead := flags.String("allow-read", "", "list of files and directories scripts may read, separated by "+string(filepath.ListSeparator))
+	allowWrite := flags.String("allow-write", "", "list of files and directories scripts may write, separated by "+string(filepath.ListSeparator))
+	allowImport := flags.String("allow-import", "", "list of files and directories scripts may import besides the search path and the directory of the script, separated by "+string(filepath.ListSeparator))
+	allowEnv := flags.String("allow-env", "", "comma-separated list of environment variables scripts may read, * for all")
+	errorValues := flags.Bool("error-values", false, "return the errors of builtins as values instead of failing")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	interpreter := &eval.Interpreter{
+		Modules: eval.NewLoader(filepath.SplitList(*path)...),
+		Capabilities: eval.Capabilities{
+			Read:    filepath.SplitList(*allowRead),
+			Write:   filepath.SplitList(*allowWrite),
+			Imports: filepath.SplitList(*allowImport),
+			Env:     splitList(*allowEnv),
+			Args:    []string{},
+			Exit:    true,
+		},
+		ErrorValues: *errorValues,
+	}
+	if flags.NArg() > 1 {
+		interpreter.Capabilities.Args = flags.Args()[1:]
+	}
+	if *seed != 0 {
+		interpreter.Random = rand.New(rand.NewSource(*seed))
+	}
+
+	var result object.Object
+
+	switch file := flags.Arg(0); file {
+	case "", "-":
+		source, err := readSource(flags, env)
+		if err != nil {
+			fmt.Fprintln(env.stderr, err)
+			return 1
+		}
+
+		errors, program := parser.New(lexer.New(source)).ParseProgram()
+		if errors != nil {
+			outputErrors(env.stderr, errors)
+			return 1
+		}
+
+		result = interpreter.Eval(program, object.NewEnvironment())
+
+	default:
+		result = interpreter.EvalFile(file)
+	}
+
+	if err, ok := result.(*object.Error); ok && err.Kind == object.EXIT_ERROR {
+		return err.Code
+	}
+
+	if err, ok := result.(*object.Error); ok {
+		fmt.Fprintln(env.stderr, err.Inspect())
+		for _, frame := range err.Stack {
+			fmt.Fprintln(env.stderr, "\tin "+frame)
+		}
+		return 1
+	}
+
+	if result.Type() != object.NULL_OBJECT {
+		fmt.Fprintln(env.stdout, result.Inspect())
+	}
+
+	return 0
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"monkey/lexer"
+	"monkey/token"
+)
+
+func runTokens(flags *flag.FlagSet, args []string, env *environment) int {
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	source, err := readSource(flags, env)
+	if err != nil {
+		fmt.Fprintln(env.stderr, err)
+		return 1
+	}
+
+	lex := lexer.New(source)
+
+	for {
+		tok := lex.GetNextToken()
+		fmt.Fprintf(env.stdout, "%-8s %-10s %q\n", tok.Position, tok.Type, tok.Literal)
+
+		if tok.Type == token.EOF {
+			return 0
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package eval
+
+import (
+	"monkey/object"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// builtins holds the functions implemented in Go by name. They are visible
+// in every environment, but bindings of the same name hide them.
+var builtins = indexBuiltins(
+	coreBuiltins,
+	stringBuiltins,
+	collectionBuiltins,
+	taskBuiltins,
+	errorBuiltins,
+)
+
+// maxLength bounds the length of the strings, arrays and channels which
+// builtins create by the numbers they get, so that builtins fail instead of
+// crashing the host if they are too large.
+const maxLength = 1 << 28
+
+// builtinModules holds the namespaces of builtins by name, e.g. math. Like
+// builtins they are visible in every environment.
+var builtinModules = map[string]*object.Module{
+	mathModule.Path: mathModule,
+	jsonModule.Path: jsonModule,
+}
+
+func indexBuiltins(groups ...[]*object.Builtin) map[string]*object.Builtin {
+	index := map[string]*object.Builtin{}
+
+	for _, group := range groups {
+		for _, builtin := range group {
+			index[builtin.Name] = builtin
+		}
+	}
+
+	return index
+}
+
+// newModule returns a builtin module which exports builtins by the part of
+// their name after the module name, e.g. math.abs as abs, and constants.
+func newModule(name string, builtins []*object.Builtin, constants map[string]object.Object) *object.Module {
+	module := &object.Module{Path: name, Names: []string{}, Exports: map[string]object.Object{}}
+
+	for _, builtin := range builtins {
+		export := strings.TrimPrefix(builtin.Name, name+".")
+		module.Names = append(module.Names, export)
+		module.Exports[export] = builtin
+	}
+
+	constantNames := []string{}
+	for constant := range constants {
+		constantNames = append(constantNames, constant)
+	}
+	slices.Sort(constantNames)
+
+	for _, constant := range constantNames {
+		module.Names = append(module.Names, constant)
+		module.Exports[constant] = constants[constant]
+	}
+
+	return module
+}
+
+var coreBuiltins = []*object.Builtin{
+	{Name: "len", Fn: builtinLen},
+	{Name: "details", Fn: builtinDetails},
+}
+
+// builtinLen returns the number of characters of a string, elements of an
+// array or pairs of a hash.
+func builtinLen(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArgumentCount("len", arguments, 1, 1); err != nil {
+		return err
+	}
+
+	switch argument := arguments[0].(type) {
+	case *object.String:
+		return &object.Integer{Value: int64(utf8.RuneCountInString(argument.Value))}
+	case *object.Array:
+		return &object.Integer{Value: int64(len(argument.Elements))}
+	case *object.Hash:
+		return &object.Integer{Value: int64(len(argument.Keys))}
+	}
+
+	return argumentTypeError("len", 0, arguments[0], object.STRING_OBJECT, object.ARRAY_OBJECT, object.HASH_OBJECT)
+}
+
+// builtinDetails returns the source text of a function and the values of
+// the variables it captured, which its signature printed by default omits.
+func builtinDetails(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("details", arguments, 1, object.FUNCTION_OBJECT); err != nil {
+		return err
+	}
+
+	return &object.String{Value: arguments[0].(*object.Function).Details()}
+}
+
+// checkArguments reports an error unless arguments has one argument of the
+// given type per parameter. Only the first required parameters must be
+// passed, the others are optional.
+func checkArguments(name string, arguments []object.Object, required int, types ...object.ObjectType) *object.Error {
+	if err := checkArgumentCount(name, arguments, required, len(types)); err != nil {
+		return err
+	}
+
+	for index, argument := range arguments {
+		if argument.Type() != types[index] {
+			return argumentTypeError(name, index, argument, types[index])
+		}
+	}
+
+	return nil
+}
+
+// checkArgumentCount reports an error unless the number of arguments is
+// within required and maximum, which is negative if there is no maximum.
+func checkArgumentCount(name string, arguments []object.Object, required, maximum int) *object.Error {
+	if err := checkCount(required, maximum, len(arguments)); err != nil {
+		return newError("%s: %s", name, err.Message)
+	}
+	return nil
+}
+
+// argumentTypeError reports that the argument at index has none of the
+// expected types.
+func argumentTypeError(name string, index int, argument object.Object, expected ...object.ObjectType) *object.Error {
+	names := []string{}
+	for _, objectType := range expected {
+		names = append(names, string(objectType))
+	}
+
+	list := names[0]
+	if count := len(names); count > 1 {
+		list = strings.Join(names[:count-1], ", ") + " or " + names[count-1]
+	}
+
+	return newError("%s: argument %d must be %s, got %s", name, index+1, list, argument.Type())
+}
@@ -0,0 +1,100 @@
+package eval
+
+import (
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testBuiltins(t *testing.T, testCases []struct{ input, expected string }) {
+	t.Helper()
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			_, program := parser.New(lexer.New(testCase.input)).ParseProgram()
+			actual := Eval(program, object.NewEnvironment())
+
+			assert.Equal(t, testCase.expected, actual.Inspect())
+		})
+	}
+}
+
+func TestBuiltins(t *testing.T) {
+	testBuiltins(t, []struct{ input, expected string }{
+		{"len", "builtin len"},
+		{"let len = 1; len", "1"},
+		{"fn() { len }()", "builtin len"},
+		{`len("äbc")`, "3"},
+		{"len([1, 2])", "2"},
+		{`len({"a": 1})`, "1"},
+		{"len(1)", "Error: len: argument 1 must be STRING, ARRAY or HASH, got INTEGER"},
+		{"len()", "Error: len: expected 1 arguments got only 0"},
+		{"len == len", "true"},
+		{"let a = 1; fn add(b) { a + b }; add", "fn add(b)"},
+		{"let a = 1; fn add(b) { a + b }; details(add)", "fn add(b) { a + b }\ncaptured:\n\ta = 1"},
+		{"details(fn() { 1 })", "fn() { 1 }"},
+		{"details(len)", "Error: details: argument 1 must be FUNCTION, got BUILTIN"},
+	})
+}
+
+func TestStringBuiltins(t *testing.T) {
+	testBuiltins(t, []struct{ input, expected string }{
+		{`split("a,b,,c", ",")`, "[a, b, , c]"},
+		{`split("äb", "")`, "[ä, b]"},
+		{`join(["a", "b"], ", ")`, "a, b"},
+		{`join(["a", "b"])`, "ab"},
+		{`join([], ",")`, ""},
+		{`trim("  a b \n")`, "a b"},
+		{`trim("xxaxx", "x")`, "a"},
+		{`contains("monkey", "key")`, "true"},
+		{`contains("monkey", "Key")`, "false"},
+		{`index_of("äbc", "c")`, "2"},
+		{`index_of("abc", "a")`, "0"},
+		{`index_of("abc", "d")`, "-1"},
+		{`replace("a-b-c", "-", "+")`, "a+b+c"},
+		{`upper("äb")`, "ÄB"},
+		{`lower("ABC")`, "abc"},
+		{`starts_with("monkey", "mon")`, "true"},
+		{`ends_with("monkey", "mon")`, "false"},
+		{`repeat("ab", 3)`, "ababab"},
+		{`repeat("ab", 0)`, ""},
+		{`substring("äbcd", 1, 3)`, "bc"},
+		{`substring("äbcd", 1)`, "bcd"},
+		{`substring("abc", -1, 10)`, "abc"},
+		{`substring("abc", 2, 1)`, ""},
+		{`format("%s is %d", "a", 1)`, "a is 1"},
+		{`format("%s %s", [1, "a"], null)`, "[1, a] null"},
+		{`format("%q", "a\"b")`, `"a\"b"`},
+		{`format("100%%")`, "100%"},
+		{`upper(format("%s", "a"))`, "A"},
+	})
+}
+
+func TestStringBuiltinErrors(t *testing.T) {
+	testBuiltins(t, []struct{ input, expected string }{
+		{`split("a")`, "Error: split: expected 2 arguments got only 1"},
+		{`split("a", ",", 1)`, "Error: split: expected 2 arguments got 3"},
+		{`split(1, ",")`, "Error: split: argument 1 must be STRING, got INTEGER"},
+		{`join(["a", 1])`, "Error: join: element 1 must be STRING, got INTEGER"},
+		{`join("a")`, "Error: join: argument 1 must be ARRAY, got STRING"},
+		{`trim("a", "b", "c")`, "Error: trim: expected at most 2 arguments got 3"},
+		{`contains("a", null)`, "Error: contains: argument 2 must be STRING, got NULL"},
+		{`repeat("a", -1)`, "Error: repeat: negative count -1"},
+		{`repeat("ab", 4611686018427387904)`, "Error: repeat: result exceeds the maximum length of 268435456 bytes"},
+		{`repeat("ab", 134217729)`, "Error: repeat: result exceeds the maximum length of 268435456 bytes"},
+		{`repeat("", 4611686018427387904)`, ""},
+		{`substring("a")`, "Error: substring: expected at least 2 arguments got only 1"},
+		{`format()`, "Error: format: expected at least 1 arguments got only 0"},
+		{`format(1)`, "Error: format: argument 1 must be STRING, got INTEGER"},
+		{`format("%d", "a")`, "Error: format: %d expects INTEGER, got STRING"},
+		{`format("%q", 1)`, "Error: format: %q expects STRING, got INTEGER"},
+		{`format("%s %s", 1)`, "Error: format: missing argument for %s"},
+		{`format("%s", 1, 2)`, "Error: format: 1 arguments unused"},
+		{`format("%x", 1)`, "Error: format: unknown verb %x"},
+		{`format("a%")`, "Error: format: missing verb at end of format"},
+		{`upper(x)`, "Error: identifier not found x"},
+	})
+}
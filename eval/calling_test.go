@@ -0,0 +1,142 @@
+package eval
+
+import (
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCallingConvention pins down the order in which a call evaluates its
+// parts and the environments they are evaluated in.
+func TestCallingConvention(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		// Evaluation order
+		{
+			"function before arguments",
+			"f(a)",
+			"Error: identifier not found f",
+		},
+		{
+			"arguments from left to right",
+			"fn(a, b) { a }(x, y)",
+			"Error: identifier not found x",
+		},
+		{
+			"arguments before non-function error",
+			"5(x)",
+			"Error: identifier not found x",
+		},
+		{
+			"arguments before arity check",
+			"fn(a) { a }(1, 2, x)",
+			"Error: identifier not found x",
+		},
+		{
+			"arity check before defaults",
+			"fn(a, b = x) { a }(1, 2, 3)",
+			"Error: expected at most 2 arguments got 3",
+		},
+		{
+			"arguments before defaults",
+			"fn(a, b = x) { b }(y)",
+			"Error: identifier not found y",
+		},
+		{
+			"arguments before body",
+			"fn(a) { x }(y)",
+			"Error: identifier not found y",
+		},
+		{
+			"nested calls before outer call",
+			"fn(a) { a }(fn() { x }())",
+			"Error: identifier not found x",
+		},
+
+		// Environments
+		{
+			"arguments in caller environment",
+			"let a = 1; fn(a, b) { b }(5, a)",
+			"1",
+		},
+		{
+			"parameters are not visible to the caller",
+			"fn(a) { a }(1); a",
+			"Error: identifier not found a",
+		},
+		{
+			"defaults see earlier parameters",
+			"fn(a, b = a * 2) { b }(3)",
+			"6",
+		},
+		{
+			"defaults do not see later parameters",
+			"fn(a = b, b = 1) { a }()",
+			"Error: identifier not found b",
+		},
+		{
+			"defaults are skipped for given arguments",
+			"fn(a = x) { a }(1)",
+			"1",
+		},
+		{
+			"defaults are evaluated in closure environment",
+			"let x = 1; let f = fn(a = x) { a }; fn(x) { f() }(2)",
+			"1",
+		},
+		{
+			"parameters shadow closure environment",
+			"let a = 1; fn(a) { a }(2)",
+			"2",
+		},
+		{
+			"lets in body stay in call",
+			"let a = 1; fn() { let a = 2; a }(); a",
+			"1",
+		},
+		{
+			"body sees later lets of closure environment",
+			"let f = fn() { x }; let x = 2; f()",
+			"2",
+		},
+		{
+			"body does not see caller environment",
+			"let f = fn() { x }; fn(x) { f() }(2)",
+			"Error: identifier not found x",
+		},
+		{
+			"closures keep their call environment",
+			"let adder = fn(x) { fn(y) { x + y } }; let one = adder(1); let two = adder(2); [one(10), two(10)]",
+			"[11, 12]",
+		},
+		{
+			"recursion gets a new environment per call",
+			"let f = fn(n) { if (n == 0) { 0 } else { let r = f(n - 1); n + r } }; f(4)",
+			"10",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, program := parser.New(lexer.New(testCase.input)).ParseProgram()
+			actual := Eval(program, object.NewEnvironment())
+
+			assert.Equal(t, testCase.expected, actual.Inspect())
+		})
+	}
+}
+
+func TestClosureCapturesEnvironmentOnce(t *testing.T) {
+	_, program := parser.New(lexer.New("fn() { 1 }")).ParseProgram()
+	env := object.NewEnvironment()
+
+	function := Eval(program, env).(*object.Function)
+
+	assert.Same(t, env, function.Env)
+}
@@ -0,0 +1,433 @@
+package eval
+
+import (
+	"monkey/object"
+	"sort"
+)
+
+// The collection builtins return new arrays and never change their
+// arguments. Callbacks are called with one element at a time, in order, and
+// the first error they return aborts the builtin.
+var collectionBuiltins = []*object.Builtin{
+	{Name: "map", Fn: builtinMap},
+	{Name: "filter", Fn: builtinFilter},
+	{Name: "reduce", Fn: builtinReduce},
+	{Name: "each", Fn: builtinEach},
+	{Name: "find", Fn: builtinFind},
+	{Name: "any", Fn: builtinAny},
+	{Name: "all", Fn: builtinAll},
+	{Name: "sort", Fn: builtinSort},
+	{Name: "zip", Fn: builtinZip},
+	{Name: "range", Fn: builtinRange},
+	{Name: "enumerate", Fn: builtinEnumerate},
+	{Name: "keys", Fn: builtinKeys},
+	{Name: "values", Fn: builtinValues},
+	{Name: "entries", Fn: builtinEntries},
+}
+
+func builtinMap(runtime object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkCallbackArguments("map", arguments); err != nil {
+		return err
+	}
+
+	elements := arrayElements(arguments[0])
+	mapped := make([]object.Object, len(elements))
+
+	for index, element := range elements {
+		value := runtime.Call(arguments[1], element)
+		if isError(value) {
+			return value
+		}
+		mapped[index] = value
+	}
+
+	return &object.Array{Elements: mapped}
+}
+
+func builtinFilter(runtime object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkCallbackArguments("filter", arguments); err != nil {
+		return err
+	}
+
+	filtered := []object.Object{}
+
+	for _, element := range arrayElements(arguments[0]) {
+		keep := runtime.Call(arguments[1], element)
+		if isError(keep) {
+			return keep
+		}
+		if runtime.IsTruthy(keep) {
+			filtered = append(filtered, element)
+		}
+	}
+
+	return &object.Array{Elements: filtered}
+}
+
+// builtinReduce combines the elements from left to right by calling the
+// callback with the result so far and the next element. Without an initial
+// value the first element is used.
+func builtinReduce(runtime object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArgumentCount("reduce", arguments, 2, 3); err != nil {
+		return err
+	}
+	if err := checkCallbackArguments("reduce", arguments[:2]); err != nil {
+		return err
+	}
+
+	elements := arrayElements(arguments[0])
+
+	var result object.Object
+	if len(arguments) > 2 {
+		result = arguments[2]
+	} else {
+		if len(elements) == 0 {
+			return newError("reduce: empty array without initial value")
+		}
+		result, elements = elements[0], elements[1:]
+	}
+
+	for _, element := range elements {
+		result = runtime.Call(arguments[1], result, element)
+		if isError(result) {
+			return result
+		}
+	}
+
+	return result
+}
+
+// builtinEach calls the callback for its side effects and returns null.
+func builtinEach(runtime object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkCallbackArguments("each", arguments); err != nil {
+		return err
+	}
+
+	for _, element := range arrayElements(arguments[0]) {
+		if value := runtime.Call(arguments[1], element); isError(value) {
+			return value
+		}
+	}
+
+	return NULL
+}
+
+// builtinFind returns the first element the callback is true for, or null.
+func builtinFind(runtime object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkCallbackArguments("find", arguments); err != nil {
+		return err
+	}
+
+	element, err := findElement(runtime, arguments, true)
+	if err != nil {
+		return err
+	}
+	if element == nil {
+		return NULL
+	}
+	return element
+}
+
+// builtinAny reports whether the callback is true for some element. It
+// stops at the first one.
+func builtinAny(runtime object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkCallbackArguments("any", arguments); err != nil {
+		return err
+	}
+
+	element, err := findElement(runtime, arguments, true)
+	if err != nil {
+		return err
+	}
+	return nativeBoolToBooleanObject(element != nil)
+}
+
+// builtinAll reports whether the callback is true for every element. It
+// stops at the first one it is false for.
+func builtinAll(runtime object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkCallbackArguments("all", arguments); err != nil {
+		return err
+	}
+
+	element, err := findElement(runtime, arguments, false)
+	if err != nil {
+		return err
+	}
+	return nativeBoolToBooleanObject(element == nil)
+}
+
+// findElement returns the first element of the array in arguments for which
+// the callback returns a value whose truthiness is truthy, or nil if there
+// is none.
+func findElement(runtime object.Runtime, arguments []object.Object, truthy bool) (object.Object, object.Object) {
+	for _, element := range arrayElements(arguments[0]) {
+		value := runtime.Call(arguments[1], element)
+		if isError(value) {
+			return nil, value
+		}
+		if runtime.IsTruthy(value) == truthy {
+			return element, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// builtinSort sorts the elements of an array, keeping equal elements in
+// order. Without comparator the elements must be all numbers or all
+// strings. A comparator is called with two elements and returns a negative
+// integer if the first one goes first, a positive one if the second one goes
+// first and zero if they are equal.
+func builtinSort(runtime object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArgumentCount("sort", arguments, 1, 2); err != nil {
+		return err
+	}
+	if arguments[0].Type() != object.ARRAY_OBJECT {
+		return argumentTypeError("sort", 0, arguments[0], object.ARRAY_OBJECT)
+	}
+
+	sorted := append([]object.Object{}, arrayElements(arguments[0])...)
+
+	compare := compareValues
+	if len(arguments) > 1 {
+		if !isCallable(arguments[1]) {
+			return argumentTypeError("sort", 1, arguments[1], object.FUNCTION_OBJECT, object.BUILTIN_OBJECT)
+		}
+
+		compare = func(a, b object.Object) (int64, *object.Error) {
+			value := runtime.Call(arguments[1], a, b)
+			if err, ok := value.(*object.Error); ok {
+				return 0, err
+			}
+
+			integer, ok := value.(*object.Integer)
+			if !ok {
+				return 0, newError("sort: comparator must return INTEGER, got %s", value.Type())
+			}
+			return integer.Value, nil
+		}
+	}
+
+	// sort cannot be stopped, so after the first error all elements
+	// compare equal
+	var err *object.Error
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if err != nil {
+			return false
+		}
+		if err = runtime.Step(); err != nil {
+			return false
+		}
+
+		var order int64
+		order, err = compare(sorted[i], sorted[j])
+		return order < 0
+	})
+
+	if err != nil {
+		return err
+	}
+	return &object.Array{Elements: sorted}
+}
+
+// compareValues orders numbers and strings by their natural order.
+func compareValues(a, b object.Object) (int64, *object.Error) {
+	integerA, okA := a.(*object.Integer)
+	integerB, okB := b.(*object.Integer)
+	if okA && okB {
+		return compareOrdered(integerA.Value, integerB.Value), nil
+	}
+
+	floatA, okA := floatValue(a)
+	floatB, okB := floatValue(b)
+	if okA && okB {
+		return compareOrdered(floatA, floatB), nil
+	}
+
+	stringA, okA := a.(*object.String)
+	stringB, okB := b.(*object.String)
+	if okA && okB {
+		return compareOrdered(stringA.Value, stringB.Value), nil
+	}
+
+	if !isNumber(a) && a.Type() != object.STRING_OBJECT {
+		return 0, newError("sort: cannot compare %s without comparator", a.Type())
+	}
+	return 0, newError("sort: cannot compare %s with %s", a.Type(), b.Type())
+}
+
+func compareOrdered[T int64 | float64 | string](a, b T) int64 {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// builtinZip pairs up the elements of arrays by their index. The result is
+// as long as the shortest array.
+func builtinZip(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArgumentCount("zip", arguments, 1, -1); err != nil {
+		return err
+	}
+
+	length := -1
+	for index, argument := range arguments {
+		array, ok := argument.(*object.Array)
+		if !ok {
+			return argumentTypeError("zip", index, argument, object.ARRAY_OBJECT)
+		}
+		if length < 0 || len(array.Elements) < length {
+			length = len(array.Elements)
+		}
+	}
+
+	zipped := make([]object.Object, length)
+	for index := range zipped {
+		tuple := make([]object.Object, len(arguments))
+		for position, argument := range arguments {
+			tuple[position] = arrayElements(argument)[index]
+		}
+		zipped[index] = &object.Array{Elements: tuple}
+	}
+
+	return &object.Array{Elements: zipped}
+}
+
+// builtinRange returns the integers from start, which defaults to 0, up to
+// but not including end, in increments of step, which defaults to 1. A
+// negative step counts down.
+func builtinRange(runtime object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("range", arguments, 1, object.INTEGER_OBJECT, object.INTEGER_OBJECT, object.INTEGER_OBJECT); err != nil {
+		return err
+	}
+
+	start, end, step := int64(0), arguments[0].(*object.Integer).Value, int64(1)
+	if len(arguments) > 1 {
+		start, end = end, arguments[1].(*object.Integer).Value
+	}
+	if len(arguments) > 2 {
+		step = arguments[2].(*object.Integer).Value
+	}
+
+	if step == 0 {
+		return newError("range: step must not be 0")
+	}
+
+	count := rangeLength(start, end, step)
+	if count > maxLength {
+		return newError("range: %d elements exceed the maximum of %d", count, maxLength)
+	}
+	if err := runtime.Reserve(int(count)+1, int64(arraySize+(elementSize+numberSize)*count)); err != nil {
+		return err
+	}
+
+	elements := make([]object.Object, count)
+	value := start
+	for index := range elements {
+		if err := runtime.Step(); err != nil {
+			return err
+		}
+		elements[index] = &object.Integer{Value: value}
+		// Wraps around after the last element, which is not used
+		value += step
+	}
+
+	return &object.Array{Elements: elements}
+}
+
+// rangeLength returns the number of integers from start up to end in
+// increments of step. It is computed without overflow for all integers.
+func rangeLength(start, end, step int64) uint64 {
+	switch {
+	case step > 0 && start < end:
+		return (uint64(end)-uint64(start)-1)/uint64(step) + 1
+	case step < 0 && start > end:
+		return (uint64(start)-uint64(end)-1)/(uint64(-(step+1))+1) + 1
+	default:
+		return 0
+	}
+}
+
+// builtinEnumerate pairs every element of an array with its index, e.g.
+// [[0, "a"], [1, "b"]].
+func builtinEnumerate(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("enumerate", arguments, 1, object.ARRAY_OBJECT); err != nil {
+		return err
+	}
+
+	elements := arrayElements(arguments[0])
+	enumerated := make([]object.Object, len(elements))
+
+	for index, element := range elements {
+		enumerated[index] = &object.Array{
+			Elements: []object.Object{&object.Integer{Value: int64(index)}, element},
+		}
+	}
+
+	return &object.Array{Elements: enumerated}
+}
+
+// builtinKeys returns the keys of a hash in insertion order.
+func builtinKeys(_ object.Runtime, arguments ...object.Object) object.Object {
+	return hashPairs("keys", arguments, func(pair object.HashPair) object.Object {
+		return pair.Key
+	})
+}
+
+// builtinValues returns the values of a hash in insertion order.
+func builtinValues(_ object.Runtime, arguments ...object.Object) object.Object {
+	return hashPairs("values", arguments, func(pair object.HashPair) object.Object {
+		return pair.Value
+	})
+}
+
+// builtinEntries returns the pairs of a hash in insertion order, e.g.
+// [["a", 1], ["b", 2]].
+func builtinEntries(_ object.Runtime, arguments ...object.Object) object.Object {
+	return hashPairs("entries", arguments, func(pair object.HashPair) object.Object {
+		return &object.Array{Elements: []object.Object{pair.Key, pair.Value}}
+	})
+}
+
+func hashPairs(name string, arguments []object.Object, convert func(pair object.HashPair) object.Object) object.Object {
+	if err := checkArguments(name, arguments, 1, object.HASH_OBJECT); err != nil {
+		return err
+	}
+
+	hash := arguments[0].(*object.Hash)
+	elements := make([]object.Object, len(hash.Keys))
+
+	for index, key := range hash.Keys {
+		elements[index] = convert(hash.Pairs[key])
+	}
+
+	return &object.Array{Elements: elements}
+}
+
+// checkCallbackArguments reports an error unless arguments are an array and
+// a function to call for its elements.
+func checkCallbackArguments(name string, arguments []object.Object) *object.Error {
+	if err := checkArgumentCount(name, arguments, 2, 2); err != nil {
+		return err
+	}
+	if arguments[0].Type() != object.ARRAY_OBJECT {
+		return argumentTypeError(name, 0, arguments[0], object.ARRAY_OBJECT)
+	}
+	if !isCallable(arguments[1]) {
+		return argumentTypeError(name, 1, arguments[1], object.FUNCTION_OBJECT, object.BUILTIN_OBJECT)
+	}
+	return nil
+}
+
+func isCallable(value object.Object) bool {
+	return value.Type() == object.FUNCTION_OBJECT || value.Type() == object.BUILTIN_OBJECT
+}
+
+func arrayElements(value object.Object) []object.Object {
+	return value.(*object.Array).Elements
+}
@@ -0,0 +1,104 @@
+package eval
+
+import (
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectionBuiltins(t *testing.T) {
+	testBuiltins(t, []struct{ input, expected string }{
+		{"map([1, 2, 3], fn(x) { x * 2 })", "[2, 4, 6]"},
+		{"map([], fn(x) { x })", "[]"},
+		{`map(["a", "b"], upper)`, "[A, B]"},
+		{"let a = [1, 2]; map(a, fn(x) { x + 1 }); a", "[1, 2]"},
+		{"filter([1, 2, 3, 4], fn(x) { x > 2 })", "[3, 4]"},
+		{"filter([1, null, 2], fn(x) { x })", "[1, 2]"},
+		{"reduce([1, 2, 3], fn(sum, x) { sum + x })", "6"},
+		{"reduce([1, 2, 3], fn(sum, x) { sum + x }, 10)", "16"},
+		{"reduce([], fn(sum, x) { sum + x }, 0)", "0"},
+		{`reduce(["a", "b"], fn(out, x) { x + out }, "")`, "ba"},
+		{"each([1, 2], fn(x) { x })", "null"},
+		{"find([1, 2, 3], fn(x) { x > 1 })", "2"},
+		{"find([1, 2, 3], fn(x) { x > 5 })", "null"},
+		{"any([1, 2], fn(x) { x > 1 })", "true"},
+		{"any([], fn(x) { true })", "false"},
+		{"all([1, 2], fn(x) { x > 1 })", "false"},
+		{"all([], fn(x) { false })", "true"},
+		{"any([1, 2], fn(x) { x == 1 ? true : undefined })", "true"},
+		{"sort([3, 1, 2])", "[1, 2, 3]"},
+		{`sort(["b", "c", "a"])`, "[a, b, c]"},
+		{"sort([])", "[]"},
+		{"sort([1, 3, 2], fn(a, b) { b - a })", "[3, 2, 1]"},
+		{`sort([[2, "a"], [1, "b"], [2, "c"], [1, "d"]], fn([a, _], [b, _]) { a - b })`, "[[1, b], [1, d], [2, a], [2, c]]"},
+		{"let a = [2, 1]; sort(a); a", "[2, 1]"},
+		{"zip([1, 2, 3], [4, 5])", "[[1, 4], [2, 5]]"},
+		{`zip([1], ["a"], [true])`, "[[1, a, true]]"},
+		{"zip([])", "[]"},
+		{"range(3)", "[0, 1, 2]"},
+		{"range(2, 5)", "[2, 3, 4]"},
+		{"range(0, 10, 4)", "[0, 4, 8]"},
+		{"range(3, 0, -1)", "[3, 2, 1]"},
+		{"range(5, 2)", "[]"},
+		{"range(-1)", "[]"},
+		{"range(9223372036854775800, 9223372036854775807, 10)", "[9223372036854775800]"},
+		{"range(-9223372036854775807, -9223372036854775807 - 1, -9223372036854775807 - 1)", "[-9223372036854775807]"},
+		{"len(range(-9223372036854775807 - 1, 9223372036854775807, 4611686018427387904))", "4"},
+		{`enumerate(["a", "b"])`, "[[0, a], [1, b]]"},
+		{`keys({"b": 1, "a": 2})`, "[b, a]"},
+		{`values({"b": 1, "a": 2})`, "[1, 2]"},
+		{`entries({"b": 1, "a": 2})`, "[[b, 1], [a, 2]]"},
+		{"keys({})", "[]"},
+		{"reduce(map(range(1, 5), fn(x) { x * x }), fn(a, b) { a + b })", "30"},
+	})
+}
+
+func TestCollectionBuiltinErrors(t *testing.T) {
+	testBuiltins(t, []struct{ input, expected string }{
+		{"map([1])", "Error: map: expected 2 arguments got only 1"},
+		{"map(1, fn(x) { x })", "Error: map: argument 1 must be ARRAY, got INTEGER"},
+		{"map([1], 1)", "Error: map: argument 2 must be FUNCTION or BUILTIN, got INTEGER"},
+		{"map([1], fn() { 1 })", "Error: expected 0 arguments got 1"},
+		{"map([1, 2], fn(x) { x == 2 ? y : x })", "Error: identifier not found y"},
+		{`map([1], upper)`, "Error: upper: argument 1 must be STRING, got INTEGER"},
+		{"filter([1], fn(x) { y })", "Error: identifier not found y"},
+		{"reduce([], fn(a, b) { a })", "Error: reduce: empty array without initial value"},
+		{"reduce([1], fn(a, b) { a }, 0, 1)", "Error: reduce: expected at most 3 arguments got 4"},
+		{"each([1], fn(x) { y })", "Error: identifier not found y"},
+		{"find([1], fn(x) { y })", "Error: identifier not found y"},
+		{"all([1], fn(x) { y })", "Error: identifier not found y"},
+		{`sort([1, "a"])`, "Error: sort: cannot compare STRING with INTEGER"},
+		{"sort([[1], [2]])", "Error: sort: cannot compare ARRAY without comparator"},
+		{"sort([1, 2], fn(a, b) { true })", "Error: sort: comparator must return INTEGER, got BOOLEAN"},
+		{"sort([1, 2], fn(a, b) { y })", "Error: identifier not found y"},
+		{"sort([1], 1)", "Error: sort: argument 2 must be FUNCTION or BUILTIN, got INTEGER"},
+		{"zip()", "Error: zip: expected at least 1 arguments got only 0"},
+		{"zip([1], 2)", "Error: zip: argument 2 must be ARRAY, got INTEGER"},
+		{"range(0, 5, 0)", "Error: range: step must not be 0"},
+		{"range(9223372036854775807)", "Error: range: 9223372036854775807 elements exceed the maximum of 268435456"},
+		{`range("a")`, "Error: range: argument 1 must be INTEGER, got STRING"},
+		{"enumerate({})", "Error: enumerate: argument 1 must be ARRAY, got HASH"},
+		{"keys([])", "Error: keys: argument 1 must be HASH, got ARRAY"},
+	})
+}
+
+func TestCallbacksUseTruthiness(t *testing.T) {
+	_, program := parser.New(lexer.New("filter([0, 1, 2], fn(x) { x })")).ParseProgram()
+
+	actual := (&Interpreter{Truthy: LooseTruthy}).Eval(program, object.NewEnvironment())
+
+	assert.Equal(t, "[1, 2]", actual.Inspect())
+}
+
+func TestCall(t *testing.T) {
+	_, program := parser.New(lexer.New("fn(a, b) { a - b }")).ParseProgram()
+	interpreter := &Interpreter{}
+	function := interpreter.Eval(program, object.NewEnvironment())
+
+	actual := interpreter.Call(function, &object.Integer{Value: 3}, &object.Integer{Value: 1})
+
+	assert.Equal(t, "2", actual.Inspect())
+}
@@ -0,0 +1,135 @@
+package eval
+
+import (
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDestructuring(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		// Let statements
+		{
+			"array",
+			"let [a, b] = [1, 2]; a + b",
+			"3",
+		},
+		{
+			"array with rest",
+			"let [a, ...rest] = [1, 2, 3]; [a, rest]",
+			"[1, [2, 3]]",
+		},
+		{
+			"empty rest",
+			"let [a, ...rest] = [1]; rest",
+			"[]",
+		},
+		{
+			"hash",
+			`let {name, "age": age} = {"name": "Ada", "age": 36, "city": "London"}; [name, age]`,
+			"[Ada, 36]",
+		},
+		{
+			"nested",
+			`let {"point": [x, _, z]} = {"point": [1, 2, 3]}; x + z`,
+			"4",
+		},
+		{
+			"value is evaluated before binding",
+			"let a = 1; let b = 2; let [a, b] = [b, a]; [a, b]",
+			"[2, 1]",
+		},
+		{
+			"literal",
+			`let ["ok", value] = ["ok", 5]; value`,
+			"5",
+		},
+
+		// Parameters
+		{
+			"array parameter",
+			"let sum = fn([a, b]) { a + b }; sum([1, 2])",
+			"3",
+		},
+		{
+			"hash parameter with default",
+			`let greet = fn({name} = {"name": "you"}) { name }; [greet(), greet({"name": "Ada"})]`,
+			"[you, Ada]",
+		},
+		{
+			"later defaults see destructured names",
+			"fn([a, b], c = a + b) { c }([1, 2])",
+			"3",
+		},
+
+		// Shape mismatches
+		{
+			"too few elements",
+			"let [a, b] = [1];",
+			"Error: expected 2 elements for [a, b], got 1",
+		},
+		{
+			"too many elements",
+			"let [a] = [1, 2];",
+			"Error: expected 1 elements for [a], got 2",
+		},
+		{
+			"too few elements for rest",
+			"let [a, b, ...c] = [1];",
+			"Error: expected at least 2 elements for [a, b, ...c], got 1",
+		},
+		{
+			"not an array",
+			"let [a] = 5;",
+			"Error: cannot destructure INTEGER with [a]",
+		},
+		{
+			"not a hash",
+			"let {a} = [1];",
+			"Error: cannot destructure ARRAY with {a}",
+		},
+		{
+			"missing key",
+			`let {name, "age": age} = {"name": "Ada"};`,
+			`Error: missing key "age" for {name, "age": age}`,
+		},
+		{
+			"literal mismatch",
+			`let ["ok", value] = ["error", 5];`,
+			"Error: error does not match \"ok\"",
+		},
+		{
+			"nested mismatch",
+			`let {"point": [x, y]} = {"point": 1};`,
+			"Error: cannot destructure INTEGER with [x, y]",
+		},
+		{
+			"parameter mismatch",
+			"fn([a, b]) { a }([1, 2, 3])",
+			"Error: expected 2 elements for [a, b], got 3",
+		},
+		{
+			"mismatch aborts the call",
+			"fn({a}, b = x) { a }({})",
+			"Error: missing key \"a\" for {a}",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			errors, program := parser.New(lexer.New(testCase.input)).ParseProgram()
+			assert.Nil(t, errors)
+
+			actual := Eval(program, object.NewEnvironment())
+
+			assert.Equal(t, testCase.expected, actual.Inspect())
+		})
+	}
+}
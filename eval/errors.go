@@ -0,0 +1,187 @@
+package eval
+
+import (
+	"monkey/ast"
+	"monkey/object"
+	"slices"
+)
+
+// The error builtins create and inspect errors as values, which scripts
+// return instead of throwing them.
+var errorBuiltins = []*object.Builtin{
+	{Name: "error", Fn: builtinError},
+	{Name: "is_error", Fn: builtinIsError},
+	unwrapBuiltin,
+}
+
+// unwrapBuiltin fails with errors on purpose, so they are not turned into
+// values again, see Interpreter.ErrorValues.
+var unwrapBuiltin = &object.Builtin{Name: "unwrap", Fn: builtinUnwrap}
+
+// maxStackFrames limits the stack of an error to its innermost frames.
+const maxStackFrames = 100
+
+// evalThrowStatement aborts the evaluation with value. Thrown error values
+// are thrown again as they are, other values become the payload of an
+// error of kind THROW.
+func (interpreter *Interpreter) evalThrowStatement(statement *ast.ThrowStatement, env *object.Environment) object.Object {
+	value := interpreter.eval(statement.Value, env)
+	if isError(value) {
+		return value
+	}
+
+	if errorValue, ok := value.(*object.ErrorValue); ok {
+		return errorValue.Error
+	}
+
+	return &object.Error{Message: value.Inspect(), Kind: object.THROW_ERROR, Value: value}
+}
+
+// evalTryExpression evaluates the try block and, if it fails with an error
+// which can be caught, the catch block with the error bound to its
+// parameter. The finally block is evaluated last in any case, unless the
+// evaluation is aborted. It only changes the result if it fails or returns.
+func (interpreter *Interpreter) evalTryExpression(expression *ast.TryExpression, env *object.Environment) object.Object {
+	result := interpreter.eval(expression.Block, env)
+
+	if err, ok := result.(*object.Error); ok && expression.Catch != nil && isCatchable(err) {
+		catchEnv := interpreter.newEnvironment(env)
+		interpreter.bind(catchEnv, expression.Parameter.Value, &object.ErrorValue{Error: err})
+
+		result = interpreter.eval(expression.Catch, catchEnv)
+	}
+
+	if err, ok := result.(*object.Error); ok && !isCatchable(err) {
+		return result
+	}
+	if expression.Finally == nil {
+		return result
+	}
+
+	final := interpreter.eval(expression.Finally, env)
+	if isError(final) || final.Type() == object.RETURN_VALUE_OBJECT {
+		return final
+	}
+
+	return result
+}
+
+// isCatchable reports whether try can catch err. Errors which abort the
+// evaluation, like exceeded limits or exit, cannot be caught.
+func isCatchable(err *object.Error) bool {
+	return err.Kind == "" || err.Kind == object.THROW_ERROR
+}
+
+// addFrame returns a copy of err with frame added to its stack if err can be
+// caught. Errors are shared, so err itself is not changed.
+func addFrame(err *object.Error, frame string) *object.Error {
+	if !isCatchable(err) || len(err.Stack) >= maxStackFrames {
+		return err
+	}
+
+	framed := *err
+	framed.Stack = append(err.Stack[:len(err.Stack):len(err.Stack)], frame)
+	return &framed
+}
+
+// A builtinCall is the runtime of a builtin with ErrorValues. It remembers
+// the errors of the functions the builtin called back.
+type builtinCall struct {
+	*Interpreter
+	callbackErrors []*object.Error
+}
+
+// Call calls function like Interpreter.Call and remembers its error.
+func (call *builtinCall) Call(function object.Object, arguments ...object.Object) object.Object {
+	result := call.Interpreter.Call(function, arguments...)
+	if err, ok := result.(*object.Error); ok {
+		call.callbackErrors = append(call.callbackErrors, err)
+	}
+	return result
+}
+
+// failedCallback reports whether err is the error of a function called back
+// by the builtin.
+func (call *builtinCall) failedCallback(err *object.Error) bool {
+	return slices.Contains(call.callbackErrors, err)
+}
+
+// builtinFailure adds builtin to the stack of err. With ErrorValues, it
+// returns errors of builtin itself as values, call is the runtime builtin
+// was called with then. Errors of the functions the builtin called back
+// still fail the evaluation.
+func (interpreter *Interpreter) builtinFailure(builtin *object.Builtin, err *object.Error, call *builtinCall) object.Object {
+	framed := addFrame(err, builtin.Inspect())
+
+	if !interpreter.ErrorValues || !isCatchable(err) || call.failedCallback(err) || builtin == unwrapBuiltin {
+		return framed
+	}
+	return &object.ErrorValue{Error: framed}
+}
+
+// errorMember returns the member name of a caught error.
+func errorMember(errorValue *object.ErrorValue, name string) object.Object {
+	err := errorValue.Error
+
+	switch name {
+	case "message":
+		return &object.String{Value: err.Message}
+	case "kind":
+		if err.Kind == "" {
+			return NULL
+		}
+		return &object.String{Value: string(err.Kind)}
+	case "stack":
+		frames := make([]object.Object, len(err.Stack))
+		for index, frame := range err.Stack {
+			frames[index] = &object.String{Value: frame}
+		}
+		return &object.Array{Elements: frames}
+	case "value":
+		if err.Value == nil {
+			return NULL
+		}
+		return err.Value
+	default:
+		return newError("ERROR_VALUE has no member %s", name)
+	}
+}
+
+// builtinError creates an error value with a message and optional data,
+// which is its value once caught.
+func builtinError(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArgumentCount("error", arguments, 1, 2); err != nil {
+		return err
+	}
+	message, ok := arguments[0].(*object.String)
+	if !ok {
+		return argumentTypeError("error", 0, arguments[0], object.STRING_OBJECT)
+	}
+
+	err := &object.Error{Message: message.Value}
+	if len(arguments) > 1 {
+		err.Value = arguments[1]
+	}
+	return &object.ErrorValue{Error: err}
+}
+
+func builtinIsError(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArgumentCount("is_error", arguments, 1, 1); err != nil {
+		return err
+	}
+
+	return nativeBoolToBooleanObject(arguments[0].Type() == object.ERROR_VALUE_OBJECT)
+}
+
+// builtinUnwrap returns its argument unless it is an error value, which it
+// fails with instead, like throw.
+func builtinUnwrap(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArgumentCount("unwrap", arguments, 1, 1); err != nil {
+		return err
+	}
+
+	if errorValue, ok := arguments[0].(*object.ErrorValue); ok {
+		return errorValue.Error
+	}
+	return arguments[0]
+}
@@ -0,0 +1,142 @@
+package eval
+
+import (
+	"context"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTryExpression(t *testing.T) {
+	testBuiltins(t, []struct{ input, expected string }{
+		{"try { 1 } catch (e) { 2 }", "1"},
+		{"try { 1 / 0 } catch (e) { e.message }", "division by zero"},
+		{"try { 1 / 0; 1 } catch (e) { 2 }", "2"},
+		{"try { len(1) } catch (e) { [e.kind, e.stack, e.value] }", "[null, [builtin len], null]"},
+		{"try { await(spawn(fn() { 1 + true })) } catch (e) { e.message }", "type mismatch INTEGER + BOOLEAN"},
+		{`try { throw "x" } catch (e) { e }`, `error("x")`},
+		{"try { throw 1 } catch (e) { 1 }; e", "Error: identifier not found e"},
+		{"try { throw 1 } catch (e) { e.code }", "Error: ERROR_VALUE has no member code"},
+		{"let e = 1; try { throw 2 } catch (e) { e.value }; e", "1"},
+		{"try { try { 1 / 0 } catch (e) { throw 2 } } catch (e) { e.value }", "2"},
+		{"try { 1 / 0 } catch (e) { len(1) }", "Error: len: argument 1 must be STRING, ARRAY or HASH, got INTEGER"},
+	})
+}
+
+func TestThrowStatement(t *testing.T) {
+	testBuiltins(t, []struct{ input, expected string }{
+		{`throw "failed"; 1`, "Error: failed"},
+		{`try { throw "boom" } catch (e) { [e.message, e.kind, e.value] }`, "[boom, THROW, boom]"},
+		{`try { throw {"code": 404} } catch (e) { [e.message, e.value.code] }`, "[{code: 404}, 404]"},
+		{"throw 1 + true", "Error: type mismatch INTEGER + BOOLEAN"},
+		{`try { try { throw "a" } catch (e) { throw e } } catch (e) { [e.message, e.kind] }`, "[a, THROW]"},
+		{`try { try { len(1) } catch (e) { throw e } } catch (e) { e.kind }`, "null"},
+	})
+}
+
+func TestFinally(t *testing.T) {
+	testBuiltins(t, []struct{ input, expected string }{
+		{
+			`let log = channel(3);
+			let result = try { send(log, "try"); throw "x" } catch (e) { send(log, "catch"); 1 } finally { send(log, "finally") };
+			[result, recv(log), recv(log), recv(log)]`,
+			"[1, try, catch, finally]",
+		},
+		{`let log = channel(1); try { try { throw "a" } finally { send(log, "finally") } } catch (e) { [e.message, recv(log)] }`, "[a, finally]"},
+		{`try { throw "a" } finally { 1 }`, "Error: a"},
+		{`try { 1 } finally { throw "b" }`, "Error: b"},
+		{`try { throw "a" } catch (e) { 1 } finally { throw "b" }`, "Error: b"},
+		{"fn f() { try { return 1 } finally { 2 }; 3 } f()", "1"},
+		{"fn f() { try { return 1 } finally { return 2 } } f()", "2"},
+		{"fn f() { try { throw 1 } catch (e) { return 2 }; 3 } f()", "2"},
+	})
+}
+
+func TestErrorStack(t *testing.T) {
+	testBuiltins(t, []struct{ input, expected string }{
+		{`fn f() { throw "x" } fn g() { f() } try { g() } catch (e) { e.stack }`, "[fn f(), fn g()]"},
+		{"let f = fn(x) { map([x], fn(y) { y / 0 }) }; try { f(1) } catch (e) { e.stack }", "[fn(y), builtin map, fn(x)]"},
+		{"fn f(x) { x } try { f() } catch (e) { e.stack }", "[fn f(x)]"},
+		{`fn f() { throw "x" } fn g() { try { f() } catch (e) { throw e } } try { g() } catch (e) { e.stack }`, "[fn f(), fn g()]"},
+	})
+}
+
+func TestAbortsCannotBeCaught(t *testing.T) {
+	loop := "let loop = fn() { loop() }; let log = channel(1); try { loop() } catch (e) { 1 } finally { send(log, 1) }"
+
+	actual := evalWithLimits(context.Background(), Limits{Steps: 1000}, loop).(*object.Error)
+	assert.Equal(t, object.LIMIT_ERROR, actual.Kind)
+	assert.Empty(t, actual.Stack)
+
+	actual = evalWithLimits(context.Background(), Limits{Memory: 1000}, `let f = fn(s) { f(s + "a") }; try { f("") } catch (e) { 1 }`).(*object.Error)
+	assert.Equal(t, object.MEMORY_ERROR, actual.Kind)
+
+	actual = evalWithCapabilities(Capabilities{Exit: true}, "try { exit(3) } catch (e) { 1 }").(*object.Error)
+	assert.Equal(t, object.EXIT_ERROR, actual.Kind)
+	assert.Equal(t, 3, actual.Code)
+}
+
+func TestThrownError(t *testing.T) {
+	actual := evalWithLimits(context.Background(), Limits{}, `fn f() { throw [1, 2] } f()`).(*object.Error)
+
+	assert.Equal(t, "[1, 2]", actual.Message)
+	assert.Equal(t, object.THROW_ERROR, actual.Kind)
+	assert.Equal(t, "[1, 2]", actual.Value.Inspect())
+	assert.Equal(t, []string{"fn f()"}, actual.Stack)
+}
+
+func TestErrorBuiltins(t *testing.T) {
+	testBuiltins(t, []struct{ input, expected string }{
+		{`error("failed")`, `error("failed")`},
+		{`let e = error("not found", {"code": 404}); [e.message, e.kind, e.value.code, e.stack]`, "[not found, null, 404, []]"},
+		{`error("failed").value`, "null"},
+		{`[is_error(error("x")), is_error(1), is_error(null)]`, "[true, false, false]"},
+		{`unwrap(1)`, "1"},
+		{`unwrap(error("failed")); 1`, "Error: failed"},
+		{`try { unwrap(error("failed", 1)) } catch (e) { [e.message, e.value, e.stack] }`, "[failed, 1, [builtin unwrap]]"},
+		{`try { throw error("failed", 2) } catch (e) { [e.message, e.value] }`, "[failed, 2]"},
+		{`fn check(x) { x > 0 ? x : error("negative") } filter(map([1, -1], check), is_error)`, `[error("negative")]`},
+		{"error(1)", "Error: error: argument 1 must be STRING, got INTEGER"},
+		{"error()", "Error: error: expected at least 1 arguments got only 0"},
+		{"is_error()", "Error: is_error: expected 1 arguments got only 0"},
+	})
+}
+
+func TestErrorValues(t *testing.T) {
+	testCases := []struct{ input, expected string }{
+		{"len(1)", `error("len: argument 1 must be STRING, ARRAY or HASH, got INTEGER")`},
+		{"let e = len(1); [is_error(e), e.stack]", "[true, [builtin len]]"},
+		{`unwrap(split("a,b", ","))`, "[a, b]"},
+		{"unwrap(len(1)); 1", "Error: len: argument 1 must be STRING, ARRAY or HASH, got INTEGER"},
+		{"map([1, [2]], len)", `[error("len: argument 1 must be STRING, ARRAY or HASH, got INTEGER"), 1]`},
+		{"is_error(await(spawn(len, 1)))", "true"},
+		{"map([1], len)", `[error("len: argument 1 must be STRING, ARRAY or HASH, got INTEGER")]`},
+		{"map([1], fn(x) { len(x) })", `[error("len: argument 1 must be STRING, ARRAY or HASH, got INTEGER")]`},
+		{"map([1], fn(x) { x / 0 })", "Error: division by zero"},
+		{"map([1], fn(x) { unwrap(len(x)) })", "Error: len: argument 1 must be STRING, ARRAY or HASH, got INTEGER"},
+		{"is_error(await(spawn(fn() { 1 / 0 })))", "true"},
+		{"1 + true", "Error: type mismatch INTEGER + BOOLEAN"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			_, program := parser.New(lexer.New(testCase.input)).ParseProgram()
+			interpreter := &Interpreter{ErrorValues: true}
+			actual := interpreter.Eval(program, object.NewEnvironment())
+
+			assert.Equal(t, testCase.expected, actual.Inspect())
+		})
+	}
+}
+
+func TestErrorValuesKeepAborts(t *testing.T) {
+	_, program := parser.New(lexer.New("let loop = fn() { loop() }; await(spawn(loop))")).ParseProgram()
+	interpreter := &Interpreter{ErrorValues: true, Limits: Limits{Steps: 1000}}
+
+	actual := interpreter.Eval(program, object.NewEnvironment()).(*object.Error)
+
+	assert.Equal(t, object.LIMIT_ERROR, actual.Kind)
+}
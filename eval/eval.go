@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"monkey/ast"
 	"monkey/object"
+	"path/filepath"
 )
 
 var (
@@ -12,63 +13,119 @@ var (
 	FALSE = &object.Boolean{Value: false}
 )
 
-func Eval(node ast.Node, env *object.Environment) object.Object {
+func (interpreter *Interpreter) eval(node ast.Node, env *object.Environment) object.Object {
+	if err := interpreter.step(); err != nil {
+		return err
+	}
+
 	switch node := node.(type) {
 
 	// Statements
 	case *ast.Program:
-		return evalProgram(node, env)
+		return interpreter.evalProgram(node, env)
 
 	case *ast.BlockStatement:
-		return evalBlockStatement(node, env)
+		return interpreter.evalBlockStatement(node, env)
 
 	case *ast.LetStatement:
-		return evalLetStatement(node, env)
+		return interpreter.evalLetStatement(node, env)
 
 	case *ast.ExpressionStatement:
-		return Eval(node.Value, env)
+		return interpreter.eval(node.Value, env)
+
+	case *ast.FunctionStatement:
+		// Declarations are bound when their block starts
+		return NULL
+
+	case *ast.ImportStatement:
+		return interpreter.evalImportStatement(node, env)
+
+	case *ast.ExportStatement:
+		return interpreter.eval(node.Statement, env)
 
 	case *ast.ReturnStatement:
-		value := Eval(node.Value, env)
+		value := interpreter.eval(node.Value, env)
 		if isError(value) {
 			return value
 		}
 		return &object.ReturnValue{Value: value}
 
+	case *ast.ThrowStatement:
+		return interpreter.evalThrowStatement(node, env)
+
 	// Expressions
 	case *ast.IntegerLiteral:
-		return &object.Integer{Value: node.Value}
+		return interpreter.allocate(&object.Integer{Value: node.Value})
+
+	case *ast.FloatLiteral:
+		return interpreter.allocate(&object.Float{Value: node.Value})
 
 	case *ast.Identifier:
-		return evalIdentifier(node, env)
+		return interpreter.evalIdentifier(node, env)
 
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(node.Value)
 
+	case *ast.NullLiteral:
+		return NULL
+
+	case *ast.StringLiteral:
+		return interpreter.allocate(&object.String{Value: node.Value})
+
 	case *ast.PrefixExpression:
-		return evalPrefixExpression(node, env)
+		return interpreter.evalPrefixExpression(node, env)
 
 	case *ast.InfixExpression:
-		return evalInfixExpression(node, env)
+		return interpreter.evalInfixExpression(node, env)
 
 	case *ast.IfExpression:
-		return evalIfExpression(node, env)
+		return interpreter.evalIfExpression(node, env)
+
+	case *ast.ConditionalExpression:
+		return interpreter.evalConditionalExpression(node, env)
+
+	case *ast.MatchExpression:
+		return interpreter.evalMatchExpression(node, env)
+
+	case *ast.TryExpression:
+		return interpreter.evalTryExpression(node, env)
 
 	case *ast.FunctionLiteral:
-		return evalFunctionLiteral(node, env)
+		return interpreter.evalFunctionLiteral(node, env)
 
 	case *ast.CallExpression:
-		return evalCallExpression(node, env)
+		value, _ := interpreter.evalCallExpression(node, env)
+		return value
+
+	case *ast.ArrayLiteral:
+		elements, err := interpreter.evalExpressions(node.Elements, env)
+		if err != nil {
+			return err
+		}
+		return interpreter.allocate(&object.Array{Elements: elements})
+
+	case *ast.HashLiteral:
+		return interpreter.evalHashLiteral(node, env)
+
+	case *ast.IndexExpression:
+		value, _ := interpreter.evalIndexExpression(node, env)
+		return value
+
+	case *ast.MemberExpression:
+		value, _ := interpreter.evalMemberExpression(node, env)
+		return value
 
 	}
 
 	return NULL
 }
 
-func evalProgram(program *ast.Program, env *object.Environment) object.Object {
+func (interpreter *Interpreter) evalProgram(program *ast.Program, env *object.Environment) object.Object {
+	interpreter.declareFunctions(program.Statements, env)
+
 	var result object.Object = NULL
 	for _, statement := range program.Statements {
-		result = Eval(statement, env)
+		result = interpreter.eval(statement, env)
 
 		switch result := result.(type) {
 		case *object.Error:
@@ -81,97 +138,136 @@ func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 	return result
 }
 
-func evalBlockStatement(blockStatement *ast.BlockStatement, env *object.Environment) object.Object {
+func (interpreter *Interpreter) evalBlockStatement(blockStatement *ast.BlockStatement, env *object.Environment) object.Object {
 	var result object.Object = NULL
 
-	innerEnv := object.NewEnclosedEnvironment(env)
+	innerEnv := interpreter.newEnvironment(env)
+	interpreter.declareFunctions(blockStatement.Statements, innerEnv)
 
 	for _, statement := range blockStatement.Statements {
-		result = Eval(statement, innerEnv)
+		result = interpreter.eval(statement, innerEnv)
 
-		if result != nil && (result.Type() == object.ERROR_OBJECT || result.Type() == object.RETURN_VALUE_OBJECT) {
+		if result.Type() == object.ERROR_OBJECT || result.Type() == object.RETURN_VALUE_OBJECT {
 			return result
 		}
 	}
 	return result
 }
 
-func evalLetStatement(letStatement *ast.LetStatement, env *object.Environment) object.Object {
-	value := Eval(letStatement.Value, env)
+// declareFunctions binds all function declarations of a block before any of
+// its statements runs. The functions share env, so they can call each other.
+func (interpreter *Interpreter) declareFunctions(statements []ast.Statement, env *object.Environment) {
+	for _, statement := range statements {
+		if export, ok := statement.(*ast.ExportStatement); ok {
+			statement = export.Statement
+		}
+
+		if declaration, ok := statement.(*ast.FunctionStatement); ok {
+			function := interpreter.evalFunctionLiteral(declaration.Function, env).(*object.Function)
+			function.Name = declaration.Name.Value
+			interpreter.bind(env, declaration.Name.Value, function)
+		}
+	}
+}
+
+func (interpreter *Interpreter) evalLetStatement(letStatement *ast.LetStatement, env *object.Environment) object.Object {
+	value := interpreter.eval(letStatement.Value, env)
 	if isError(value) {
 		return value
 	}
 
-	env.Set(letStatement.Name.Value, value)
+	if mismatch := interpreter.destructure(letStatement.Name, value, env); mismatch != nil {
+		return mismatch.error()
+	}
 
-	return nil
+	return NULL
 }
 
-func evalIdentifier(identifier *ast.Identifier, env *object.Environment) object.Object {
-	value, ok := env.Get(identifier.Value)
-	if !ok {
-		return newError("identifier not found %s", identifier.Value)
+func (interpreter *Interpreter) evalIdentifier(identifier *ast.Identifier, env *object.Environment) object.Object {
+	if value, ok := env.Get(identifier.Value); ok {
+		return value
+	}
+	if builtin, ok := builtins[identifier.Value]; ok {
+		return builtin
+	}
+	if module, ok := builtinModules[identifier.Value]; ok {
+		return module
 	}
-	return value
+	if builtin, ok := interpreter.hostBuiltin(identifier.Value); ok {
+		return builtin
+	}
+	return newError("identifier not found %s", identifier.Value)
 }
 
-func evalPrefixExpression(expression *ast.PrefixExpression, env *object.Environment) object.Object {
-	right := Eval(expression.Right, env)
+func (interpreter *Interpreter) evalPrefixExpression(expression *ast.PrefixExpression, env *object.Environment) object.Object {
+	right := interpreter.eval(expression.Right, env)
 	if isError(right) {
 		return right
 	}
 
 	switch expression.Operator {
 	case "-":
-		return evalPrefixMinusOperator(right)
+		return interpreter.allocate(evalPrefixMinusOperator(right))
 	case "!":
-		return evalBangOperator(right)
+		return interpreter.evalBangOperator(right)
 	default:
 		return NULL
 	}
 }
 
 func evalPrefixMinusOperator(right object.Object) object.Object {
-	if integer, ok := right.(*object.Integer); ok {
-		return &object.Integer{
-			Value: -integer.Value,
-		}
+	switch right := right.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: -right.Value}
+	case *object.Float:
+		return &object.Float{Value: -right.Value}
 	}
 	return newError(
 		"unknown operation -%s", right.Type(),
 	)
 }
 
-func evalBangOperator(right object.Object) object.Object {
-	switch right {
-	case TRUE:
-		return FALSE
-	case FALSE:
-		return TRUE
-	case NULL:
-		return TRUE
-	default:
-		return FALSE
-	}
+func (interpreter *Interpreter) evalBangOperator(right object.Object) object.Object {
+	return nativeBoolToBooleanObject(!interpreter.IsTruthy(right))
 }
 
-func evalInfixExpression(expression *ast.InfixExpression, env *object.Environment) object.Object {
+func (interpreter *Interpreter) evalInfixExpression(expression *ast.InfixExpression, env *object.Environment) object.Object {
 	operator := expression.Operator
-	left := Eval(expression.Left, env)
+	left := interpreter.eval(expression.Left, env)
 	if isError(left) {
 		return left
 	}
 
-	right := Eval(expression.Right, env)
+	// The right operand of ?? is only evaluated if the left one is null
+	if operator == "??" {
+		if left != NULL {
+			return left
+		}
+		return interpreter.eval(expression.Right, env)
+	}
+
+	right := interpreter.eval(expression.Right, env)
 	if isError(right) {
 		return right
 	}
 
 	if left.Type() == object.INTEGER_OBJECT && right.Type() == object.INTEGER_OBJECT {
-		return evalIntegerInfixExpression(operator, left, right)
+		return interpreter.allocate(evalIntegerInfixExpression(operator, left, right))
 	}
 
-	if left.Type() != right.Type() {
+	// An integer operand is converted if the other one is a float
+	if isNumber(left) && isNumber(right) {
+		return interpreter.allocate(evalFloatInfixExpression(operator, left, right))
+	}
+
+	if left.Type() == object.STRING_OBJECT && right.Type() == object.STRING_OBJECT {
+		return interpreter.allocate(evalStringInfixExpression(operator, left, right))
+	}
+
+	// Every value can be compared with null
+	isNullComparison := (left == NULL || right == NULL) && (operator == "==" || operator == "!=")
+
+	if left.Type() != right.Type() && !isNullComparison {
 		return newError(
 			"type mismatch %s %s %s",
 			left.Type(), operator, right.Type(),
@@ -203,6 +299,9 @@ func evalIntegerInfixExpression(operator string, left object.Object, right objec
 	case "*":
 		return &object.Integer{Value: leftValue * rightValue}
 	case "/":
+		if rightValue == 0 {
+			return newError("division by zero")
+		}
 		return &object.Integer{Value: leftValue / rightValue}
 	case "<":
 		return nativeBoolToBooleanObject(leftValue < rightValue)
@@ -220,70 +319,402 @@ func evalIntegerInfixExpression(operator string, left object.Object, right objec
 	}
 }
 
-func evalIfExpression(expression *ast.IfExpression, env *object.Environment) object.Object {
-	condition := Eval(expression.Condition, env)
+func evalFloatInfixExpression(operator string, left object.Object, right object.Object) object.Object {
+	leftValue, _ := floatValue(left)
+	rightValue, _ := floatValue(right)
+
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftValue + rightValue}
+	case "-":
+		return &object.Float{Value: leftValue - rightValue}
+	case "*":
+		return &object.Float{Value: leftValue * rightValue}
+	case "/":
+		if rightValue == 0 {
+			return newError("division by zero")
+		}
+		return &object.Float{Value: leftValue / rightValue}
+	case "<":
+		return nativeBoolToBooleanObject(leftValue < rightValue)
+	case ">":
+		return nativeBoolToBooleanObject(leftValue > rightValue)
+	case "==":
+		return nativeBoolToBooleanObject(leftValue == rightValue)
+	case "!=":
+		return nativeBoolToBooleanObject(leftValue != rightValue)
+	default:
+		return newError(
+			"unknown float operation %s %s %s",
+			left.Type(), operator, right.Type(),
+		)
+	}
+}
+
+func isNumber(value object.Object) bool {
+	return value.Type() == object.INTEGER_OBJECT || value.Type() == object.FLOAT_OBJECT
+}
+
+// floatValue returns the value of an integer or float as float.
+func floatValue(value object.Object) (float64, bool) {
+	switch value := value.(type) {
+	case *object.Integer:
+		return float64(value.Value), true
+	case *object.Float:
+		return value.Value, true
+	}
+	return 0, false
+}
+
+func evalStringInfixExpression(operator string, left object.Object, right object.Object) object.Object {
+	leftValue := left.(*object.String).Value
+	rightValue := right.(*object.String).Value
+
+	switch operator {
+	case "+":
+		return &object.String{Value: leftValue + rightValue}
+	case "==":
+		return nativeBoolToBooleanObject(leftValue == rightValue)
+	case "!=":
+		return nativeBoolToBooleanObject(leftValue != rightValue)
+	default:
+		return newError(
+			"unknown operation %s %s %s",
+			left.Type(), operator, right.Type(),
+		)
+	}
+}
+
+func (interpreter *Interpreter) evalIfExpression(expression *ast.IfExpression, env *object.Environment) object.Object {
+	condition := interpreter.eval(expression.Condition, env)
 	if isError(condition) {
 		return condition
 	}
 
-	if isTruthy(condition) {
-		return Eval(expression.Consequence, env)
+	if interpreter.IsTruthy(condition) {
+		return interpreter.eval(expression.Consequence, env)
 	}
 
 	if expression.Alternative != nil {
-		return Eval(expression.Alternative, env)
+		return interpreter.eval(expression.Alternative, env)
 	}
 
-	return nil
+	return NULL
 }
 
-func evalFunctionLiteral(expression *ast.FunctionLiteral, env *object.Environment) object.Object {
-	function := &object.Function{
+func (interpreter *Interpreter) evalConditionalExpression(expression *ast.ConditionalExpression, env *object.Environment) object.Object {
+	condition := interpreter.eval(expression.Condition, env)
+	if isError(condition) {
+		return condition
+	}
+
+	if interpreter.IsTruthy(condition) {
+		return interpreter.eval(expression.Consequence, env)
+	}
+
+	return interpreter.eval(expression.Alternative, env)
+}
+
+// evalMatchExpression evaluates the body of the first arm whose pattern
+// matches the subject. The names bound by the pattern are only visible in
+// the body of its arm.
+func (interpreter *Interpreter) evalMatchExpression(expression *ast.MatchExpression, env *object.Environment) object.Object {
+	subject := interpreter.eval(expression.Subject, env)
+	if isError(subject) {
+		return subject
+	}
+
+	for _, arm := range expression.Arms {
+		armEnv := interpreter.newEnvironment(env)
+
+		if interpreter.matchPattern(arm.Pattern, subject, armEnv) {
+			return interpreter.eval(arm.Body, armEnv)
+		}
+	}
+
+	return newError("no pattern matches %s", subject.Inspect())
+}
+
+// evalFunctionLiteral creates a closure. It captures the environment it is
+// defined in by reference, so it sees later changes to that environment.
+func (interpreter *Interpreter) evalFunctionLiteral(expression *ast.FunctionLiteral, env *object.Environment) object.Object {
+	return interpreter.allocate(&object.Function{
 		Parameters: expression.Parameters,
 		Body:       expression.Body,
-		Env:        object.NewEnclosedEnvironment(env),
+		Source:     expression.Source,
+		Env:        env,
+	})
+}
+
+// evalCallExpression follows the calling convention of Monkey:
+//
+//  1. The function expression is evaluated.
+//  2. All arguments are evaluated from left to right in the environment of
+//     the caller.
+//  3. The number of arguments is checked against the parameters.
+//  4. Parameters are bound from left to right in a new environment enclosed
+//     by the environment of the closure. Missing arguments are replaced by
+//     their default, which is evaluated in the new environment, so it can
+//     refer to the parameters before it.
+//  5. The body is evaluated in the new environment.
+//
+// The first error aborts the call, no later step is run.
+func (interpreter *Interpreter) evalCallExpression(expression *ast.CallExpression, env *object.Environment) (object.Object, bool) {
+	function, skipped := interpreter.evalChain(expression.Function, env)
+	if skipped || isError(function) {
+		return function, skipped
+	}
+
+	arguments, err := interpreter.evalExpressions(expression.Arguments, env)
+	if err != nil {
+		return err, false
 	}
 
-	return function
+	return interpreter.applyFunction(function, arguments), false
 }
 
-func evalCallExpression(expression *ast.CallExpression, env *object.Environment) object.Object {
-	function := Eval(expression.Function, env)
+// evalChain evaluates the operand of a call, index or member expression.
+// Together they form a chain like a?.b[0].c(). If an optional link of the
+// chain finds null, the rest of the chain is skipped and the whole chain
+// evaluates to null, which is reported by skipped.
+func (interpreter *Interpreter) evalChain(expression ast.Expression, env *object.Environment) (value object.Object, skipped bool) {
+	switch expression := expression.(type) {
+	case *ast.CallExpression:
+		return interpreter.evalCallExpression(expression, env)
+	case *ast.IndexExpression:
+		return interpreter.evalIndexExpression(expression, env)
+	case *ast.MemberExpression:
+		return interpreter.evalMemberExpression(expression, env)
+	default:
+		return interpreter.eval(expression, env), false
+	}
+}
 
-	if isError(function) {
-		return function
+func (interpreter *Interpreter) applyFunction(function object.Object, arguments []object.Object) object.Object {
+	if builtin, ok := function.(*object.Builtin); ok {
+		// Builtins called back by builtins evaluate no node
+		if err := interpreter.step(); err != nil {
+			return err
+		}
+
+		var runtime object.Runtime = interpreter
+		var call *builtinCall
+		if interpreter.ErrorValues {
+			call = &builtinCall{Interpreter: interpreter}
+			runtime = call
+		}
+
+		result := builtin.Fn(runtime, arguments...)
+		// Builtins which wait fail once the evaluation is canceled
+		if err := interpreter.checkContext(); err != nil {
+			return err
+		}
+		if err, ok := result.(*object.Error); ok {
+			return interpreter.builtinFailure(builtin, err, call)
+		}
+		return interpreter.allocate(result)
 	}
 
 	functionObj, ok := function.(*object.Function)
-
 	if !ok {
 		return newError("invalid function call on %s", function.Inspect())
 	}
 
-	if len(expression.Arguments) < len(functionObj.Parameters) {
-		return newError("expected %d arguments got only %d", len(functionObj.Parameters), len(expression.Arguments))
+	err := interpreter.enterCall()
+	defer interpreter.leaveCall()
+	if err != nil {
+		return err
 	}
 
-	callEnv := object.NewEnclosedEnvironment(functionObj.Env)
+	callEnv, result := interpreter.bindParameters(functionObj, arguments)
+	if result == nil {
+		result = interpreter.eval(functionObj.Body, callEnv)
+	}
 
-	for index, param := range functionObj.Parameters {
-		argument := Eval(expression.Arguments[index], env)
+	switch result := result.(type) {
+	case *object.ReturnValue:
+		// Unwrap return value
+		return result.Value
+	case *object.Error:
+		return addFrame(result, functionObj.Inspect())
+	}
+
+	return result
+}
+
+func (interpreter *Interpreter) bindParameters(function *object.Function, arguments []object.Object) (*object.Environment, object.Object) {
+	if err := checkArity(function, len(arguments)); err != nil {
+		return nil, err
+	}
+
+	callEnv := interpreter.newEnvironment(function.Env)
 
-		if isError(argument) {
-			return argument
+	for index, param := range function.Parameters {
+		var value object.Object
+
+		switch {
+		case param.Rest:
+			rest := []object.Object{}
+			if index < len(arguments) {
+				rest = append(rest, arguments[index:]...)
+			}
+			value = &object.Array{Elements: rest}
+
+		case index < len(arguments):
+			value = arguments[index]
+
+		default:
+			value = interpreter.eval(param.Default, callEnv)
+			if isError(value) {
+				return nil, value
+			}
 		}
 
-		callEnv.Set(param.Value, argument)
+		if mismatch := interpreter.destructure(param.Name, value, callEnv); mismatch != nil {
+			return nil, mismatch.error()
+		}
 	}
 
-	result := Eval(functionObj.Body, callEnv)
-	returnValue, ok := result.(*object.ReturnValue)
-	if ok {
-		// Unwrap return value
-		return returnValue.Value
+	return callEnv, nil
+}
+
+func checkArity(function *object.Function, count int) *object.Error {
+	required, maximum := ast.Arity(function.Parameters)
+	return checkCount(required, maximum, count)
+}
+
+// checkCount reports an error unless count arguments are within required
+// and maximum, which is negative if there is no maximum.
+func checkCount(required, maximum, count int) *object.Error {
+	isExact := required == maximum
+
+	if count < required {
+		if isExact {
+			return newError("expected %d arguments got only %d", required, count)
+		}
+		return newError("expected at least %d arguments got only %d", required, count)
 	}
 
-	return result
+	if maximum >= 0 && count > maximum {
+		if isExact {
+			return newError("expected %d arguments got %d", maximum, count)
+		}
+		return newError("expected at most %d arguments got %d", maximum, count)
+	}
+
+	return nil
+}
+
+func (interpreter *Interpreter) evalExpressions(expressions []ast.Expression, env *object.Environment) ([]object.Object, object.Object) {
+	values := []object.Object{}
+
+	for _, expression := range expressions {
+		value := interpreter.eval(expression, env)
+		if isError(value) {
+			return nil, value
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+func (interpreter *Interpreter) evalIndexExpression(expression *ast.IndexExpression, env *object.Environment) (object.Object, bool) {
+	left, skipped := interpreter.evalChain(expression.Left, env)
+	if skipped || isError(left) {
+		return left, skipped
+	}
+
+	if expression.Optional && left == NULL {
+		return NULL, true
+	}
+
+	index := interpreter.eval(expression.Index, env)
+	if isError(index) {
+		return index, false
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		if integer, ok := index.(*object.Integer); ok {
+			if integer.Value < 0 || integer.Value >= int64(len(left.Elements)) {
+				return NULL, false
+			}
+			return left.Elements[integer.Value], false
+		}
+
+	case *object.Hash:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", index.Type()), false
+		}
+
+		if value, ok := left.Get(key); ok {
+			return value, false
+		}
+		return NULL, false
+	}
+
+	return newError("index operator not supported: %s[%s]", left.Type(), index.Type()), false
+}
+
+func (interpreter *Interpreter) evalMemberExpression(expression *ast.MemberExpression, env *object.Environment) (object.Object, bool) {
+	value, skipped := interpreter.evalChain(expression.Object, env)
+	if skipped || isError(value) {
+		return value, skipped
+	}
+
+	if expression.Optional && value == NULL {
+		return NULL, true
+	}
+
+	name := expression.Property.Value
+
+	if errorValue, ok := value.(*object.ErrorValue); ok {
+		return errorMember(errorValue, name), false
+	}
+
+	if module, ok := value.(*object.Module); ok {
+		if member, ok := module.Exports[name]; ok {
+			return member, false
+		}
+		return newError("%s does not export %s", filepath.Base(module.Path), name), false
+	}
+
+	hash, ok := value.(*object.Hash)
+	if !ok {
+		return newError("member access not supported: %s.%s", value.Type(), name), false
+	}
+
+	if member, ok := hash.Get(&object.String{Value: name}); ok {
+		return member, false
+	}
+	return NULL, false
+}
+
+func (interpreter *Interpreter) evalHashLiteral(hashLiteral *ast.HashLiteral, env *object.Environment) object.Object {
+	hash := object.NewHash()
+
+	for _, pair := range hashLiteral.Pairs {
+		key := interpreter.eval(pair.Key, env)
+		if isError(key) {
+			return key
+		}
+
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", key.Type())
+		}
+
+		value := interpreter.eval(pair.Value, env)
+		if isError(value) {
+			return value
+		}
+
+		hash.Set(hashKey, value)
+	}
+
+	return interpreter.allocate(hash)
 }
 
 func nativeBoolToBooleanObject(input bool) *object.Boolean {
@@ -293,17 +724,12 @@ func nativeBoolToBooleanObject(input bool) *object.Boolean {
 	return FALSE
 }
 
-func isTruthy(value object.Object) bool {
-	switch value {
-	case TRUE:
-		return true
-	case FALSE:
-		return false
-	case NULL:
-		return false
-	default:
-		return true
+// IsTruthy reports whether value counts as true in conditions.
+func (interpreter *Interpreter) IsTruthy(value object.Object) bool {
+	if interpreter.Truthy != nil {
+		return interpreter.Truthy(value)
 	}
+	return DefaultTruthy(value)
 }
 
 func newError(format string, a ...any) *object.Error {
@@ -114,7 +114,7 @@ func TestEval(t *testing.T) {
 		},
 		{
 			"if (2 < 1) { 5; }",
-			nil,
+			&object.Null{},
 		},
 		{
 			"if (10 == 5) { 5; } else { 10; }",
@@ -155,7 +155,7 @@ func TestEval(t *testing.T) {
 		},
 		{
 			"let x = 5;",
-			nil,
+			&object.Null{},
 		},
 		{
 			"if (true) { let a = 5; a; };",
@@ -168,27 +168,54 @@ func TestEval(t *testing.T) {
 		{
 			"fn (a) { return a; }",
 			&object.Function{
-				Parameters: []*ast.Identifier{
-					{Token: token.Token{Type: token.IDENT, Literal: "a"}, Value: "a"},
+				Parameters: []*ast.Parameter{
+					{
+						Token: token.Token{
+							Type:     token.IDENT,
+							Literal:  "a",
+							Position: token.Position{Line: 1, Column: 5},
+						},
+						Name: &ast.Identifier{
+							Token: token.Token{
+								Type:     token.IDENT,
+								Literal:  "a",
+								Position: token.Position{Line: 1, Column: 5},
+							},
+							Value: "a",
+						},
+					},
 				},
 				Body: &ast.BlockStatement{
 					Token: token.Token{
-						Type:    token.LBRACE,
-						Literal: "{",
+						Type:     token.LBRACE,
+						Literal:  "{",
+						Position: token.Position{Line: 1, Column: 8},
 					},
 					Statements: []ast.Statement{
 						&ast.ReturnStatement{
 							Token: token.Token{
-								Type:    token.RETURN,
-								Literal: "return",
+								Type:     token.RETURN,
+								Literal:  "return",
+								Position: token.Position{Line: 1, Column: 10},
 							},
 							Value: &ast.Identifier{
-								Token: token.Token{Type: token.IDENT, Literal: "a"}, Value: "a",
+								Token: token.Token{
+									Type:     token.IDENT,
+									Literal:  "a",
+									Position: token.Position{Line: 1, Column: 17},
+								},
+								Value: "a",
 							},
 						},
 					},
+					EndToken: token.Token{
+						Type:     token.RBRACE,
+						Literal:  "}",
+						Position: token.Position{Line: 1, Column: 20},
+					},
 				},
-				Env: object.NewEnclosedEnvironment(object.NewEnvironment()),
+				Source: "fn (a) { return a; }",
+				Env:    object.NewEnvironment(),
 			},
 		},
 		{
@@ -203,6 +230,290 @@ func TestEval(t *testing.T) {
 			"fn (a, b) { return a + b; }(1, 2); 10;",
 			&object.Integer{Value: 10},
 		},
+		{
+			"[1, 2 * 3, true]",
+			&object.Array{
+				Elements: []object.Object{
+					&object.Integer{Value: 1},
+					&object.Integer{Value: 6},
+					&object.Boolean{Value: true},
+				},
+			},
+		},
+		{
+			"[1, 2, 3][1 + 1]",
+			&object.Integer{Value: 3},
+		},
+		{
+			"let a = [1, [2, 3]]; a[1][0];",
+			&object.Integer{Value: 2},
+		},
+		{
+			"[1, 2, 3][3]",
+			&object.Null{},
+		},
+		{
+			"[1, 2, 3][-1]",
+			&object.Null{},
+		},
+		{
+			"fn (a, ...rest) { rest }(1, 2, 3)",
+			&object.Array{
+				Elements: []object.Object{
+					&object.Integer{Value: 2},
+					&object.Integer{Value: 3},
+				},
+			},
+		},
+		{
+			"fn (a, ...rest) { rest }(1)",
+			&object.Array{Elements: []object.Object{}},
+		},
+		{
+			"fn (a, b = 2) { a * b }(5)",
+			&object.Integer{Value: 10},
+		},
+		{
+			"fn (a, b = 2) { a * b }(5, 3)",
+			&object.Integer{Value: 15},
+		},
+		{
+			"fn (a, b = a + 1, ...rest) { [a, b, rest] }(1)",
+			&object.Array{
+				Elements: []object.Object{
+					&object.Integer{Value: 1},
+					&object.Integer{Value: 2},
+					&object.Array{Elements: []object.Object{}},
+				},
+			},
+		},
+		{
+			"let x = 1; let f = fn (a = x) { a }; let x = 2; f();",
+			&object.Integer{Value: 2},
+		},
+		{
+			"null",
+			&object.Null{},
+		},
+		{
+			`"hello" + " " + "world"`,
+			&object.String{Value: "hello world"},
+		},
+		{
+			`"a" == "a"`,
+			TRUE,
+		},
+		{
+			`"a" != "a"`,
+			FALSE,
+		},
+		{
+			"null == null",
+			TRUE,
+		},
+		{
+			"if (2 < 1) { 1 } == null",
+			TRUE,
+		},
+		{
+			"let a = if (false) { 1 }; a ?? 2",
+			&object.Integer{Value: 2},
+		},
+		{
+			"fn() {}()",
+			&object.Null{},
+		},
+		{
+			"fn() { let a = 1; }()",
+			&object.Null{},
+		},
+		{
+			"fn f() {}",
+			&object.Null{},
+		},
+		{
+			"if (true) {}",
+			&object.Null{},
+		},
+		{
+			"1 == null",
+			FALSE,
+		},
+		{
+			"null != [1]",
+			TRUE,
+		},
+		{
+			`let one = 1; {"one": one, 2: "two", true: 3, "one": 4}`,
+			func() object.Object {
+				hash := object.NewHash()
+				hash.Set(&object.String{Value: "one"}, &object.Integer{Value: 4})
+				hash.Set(&object.Integer{Value: 2}, &object.String{Value: "two"})
+				hash.Set(TRUE, &object.Integer{Value: 3})
+				return hash
+			}(),
+		},
+		{
+			`{"a": 1}["a"]`,
+			&object.Integer{Value: 1},
+		},
+		{
+			`{"a": 1}["b"]`,
+			&object.Null{},
+		},
+		{
+			`{true: 1, 2: 2}[1 == 1] + {true: 1, 2: 2}[2]`,
+			&object.Integer{Value: 3},
+		},
+		{
+			`let person = {"name": "Ada", "address": {"city": "London"}}; person.address.city`,
+			&object.String{Value: "London"},
+		},
+		{
+			`{"a": 1}.b`,
+			&object.Null{},
+		},
+		{
+			"null ?? 5",
+			&object.Integer{Value: 5},
+		},
+		{
+			"false ?? 5",
+			FALSE,
+		},
+		{
+			"1 ?? undefined",
+			&object.Integer{Value: 1},
+		},
+		{
+			"let a = null; a?.b",
+			&object.Null{},
+		},
+		{
+			"let a = null; a?.b.c[0](1)",
+			&object.Null{},
+		},
+		{
+			"let a = null; a?[0]",
+			&object.Null{},
+		},
+		{
+			"let a = null; a?[undefined]",
+			&object.Null{},
+		},
+		{
+			"[[1]]?[0]?[0]",
+			&object.Integer{Value: 1},
+		},
+		{
+			`let a = {"b": null}; a.b?.c ?? "default"`,
+			&object.String{Value: "default"},
+		},
+		{
+			`let a = {"f": fn(x) { x * 2 }}; a?.f(2)`,
+			&object.Integer{Value: 4},
+		},
+		{
+			"fn add(a, b) { a + b } add(1, 2)",
+			&object.Integer{Value: 3},
+		},
+		{
+			"let sum = add(1, 2); fn add(a, b) { a + b } sum",
+			&object.Integer{Value: 3},
+		},
+		{
+			"fn even(n) { if (n == 0) { true } else { odd(n - 1) } } fn odd(n) { if (n == 0) { false } else { even(n - 1) } } [even(10), odd(7), even(3)]",
+			&object.Array{
+				Elements: []object.Object{TRUE, TRUE, FALSE},
+			},
+		},
+		{
+			"fn f() { g() } fn g() { x } let x = 5; f()",
+			&object.Integer{Value: 5},
+		},
+		{
+			"let f = 1; fn f() { 2 } f",
+			&object.Integer{Value: 1},
+		},
+		{
+			"fn f() { 1 } if (true) { fn f() { 2 } f() }",
+			&object.Integer{Value: 2},
+		},
+		{
+			"fn f() { 1 } if (true) { fn f() { 2 } }; f()",
+			&object.Integer{Value: 1},
+		},
+		{
+			"1 < 2 ? 10 : 20",
+			&object.Integer{Value: 10},
+		},
+		{
+			"null ? 10 : 0 ? 20 : 30",
+			&object.Integer{Value: 20},
+		},
+		{
+			"true ? 1 : undefined",
+			&object.Integer{Value: 1},
+		},
+		{
+			`let describe = fn(x) { match (x) { 0 => "zero", -1 => "minus one", "a" => "letter", true => "yes", null => "nothing", _ => "other" } }; [describe(0), describe(-1), describe("a"), describe(true), describe(null), describe(false)]`,
+			&object.Array{
+				Elements: []object.Object{
+					&object.String{Value: "zero"},
+					&object.String{Value: "minus one"},
+					&object.String{Value: "letter"},
+					&object.String{Value: "yes"},
+					&object.String{Value: "nothing"},
+					&object.String{Value: "other"},
+				},
+			},
+		},
+		{
+			`let describe = fn(x) { match (x) { 1.5 => "one and a half", -0.5 => "minus half", 2.0 => "two", [1.0] => "one", _ => "other" } }; [describe(1.5), describe(-0.5), describe(2), describe([1]), describe(1.25)]`,
+			&object.Array{
+				Elements: []object.Object{
+					&object.String{Value: "one and a half"},
+					&object.String{Value: "minus half"},
+					&object.String{Value: "two"},
+					&object.String{Value: "one"},
+					&object.String{Value: "other"},
+				},
+			},
+		},
+		{
+			"match (1 + 1) { 1 => 10, n => n * 10 }",
+			&object.Integer{Value: 20},
+		},
+		{
+			"match ([1, 2]) { [] => 0, [a] => a, [a, b] => a + b, _ => -1 }",
+			&object.Integer{Value: 3},
+		},
+		{
+			"match ([1, 2, 3]) { [a, b] => 0, [a, ...rest] => rest }",
+			&object.Array{
+				Elements: []object.Object{&object.Integer{Value: 2}, &object.Integer{Value: 3}},
+			},
+		},
+		{
+			"match ([1, [2, 3]]) { [1, [_, x]] => x }",
+			&object.Integer{Value: 3},
+		},
+		{
+			`match ({"name": "monkey", "legs": 2}) { {"legs": 4} => 4, {name, "legs": 2} => name }`,
+			&object.String{Value: "monkey"},
+		},
+		{
+			`match ({"a": 1}) { {"b": b} => b, _ => 0 }`,
+			&object.Integer{Value: 0},
+		},
+		{
+			"let x = 1; match (2) { x => x }; x",
+			&object.Integer{Value: 1},
+		},
+		{
+			"let f = fn() { match (1) { 1 => { return 10; 20 } }; 30 }; f()",
+			&object.Integer{Value: 10},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -265,6 +576,114 @@ func TestEvalErrors(t *testing.T) {
 			"fn(a, b) { a + b; }(2)",
 			"expected 2 arguments got only 1",
 		},
+		{
+			"fn(a) { a; }(1, 2)",
+			"expected 1 arguments got 2",
+		},
+		{
+			"fn(a, b = 1) { a; }()",
+			"expected at least 1 arguments got only 0",
+		},
+		{
+			"fn(a, b = 1) { a; }(1, 2, 3)",
+			"expected at most 2 arguments got 3",
+		},
+		{
+			"fn(a, ...rest) { a; }()",
+			"expected at least 1 arguments got only 0",
+		},
+		{
+			"fn(a, ...rest) { a; }(1, 2, b)",
+			"identifier not found b",
+		},
+		{
+			"fn(a = b) { a; }()",
+			"identifier not found b",
+		},
+		{
+			"if (true) { fn f() { 1 } }; f()",
+			"identifier not found f",
+		},
+		{
+			"5[0]",
+			"index operator not supported: INTEGER[INTEGER]",
+		},
+		{
+			`"a" - "b"`,
+			"unknown operation STRING - STRING",
+		},
+		{
+			`"a" + 1`,
+			"type mismatch STRING + INTEGER",
+		},
+		{
+			"null < 1",
+			"type mismatch NULL < INTEGER",
+		},
+		{
+			"{[1]: 2}",
+			"unusable as hash key: ARRAY",
+		},
+		{
+			`{"a": 1}[fn() {}]`,
+			"unusable as hash key: FUNCTION",
+		},
+		{
+			"null.a",
+			"member access not supported: NULL.a",
+		},
+		{
+			"[1].length",
+			"member access not supported: ARRAY.length",
+		},
+		{
+			"let a = {}; a?.b.c",
+			"member access not supported: NULL.c",
+		},
+		{
+			"null[0]",
+			"index operator not supported: NULL[INTEGER]",
+		},
+		{
+			"null ?? undefined",
+			"identifier not found undefined",
+		},
+		{
+			"let a = null; a?.b(undefined) ?? c",
+			"identifier not found c",
+		},
+		{
+			"[1][true]",
+			"index operator not supported: ARRAY[BOOLEAN]",
+		},
+		{
+			"undefined ? 1 : 2",
+			"identifier not found undefined",
+		},
+		{
+			"1 / 0",
+			"division by zero",
+		},
+		{
+			"1.5 / 0",
+			"division by zero",
+		},
+		{
+			"1.5 + true",
+			"type mismatch FLOAT + BOOLEAN",
+		},
+		{
+			`{1.5: 1}`,
+			"unusable as hash key: FLOAT",
+		},
+		{
+			"match (3) { 1 => 1, [a] => a }",
+			"no pattern matches 3",
+		},
+		{
+			"match ([1]) { [a] => undefined }",
+			"identifier not found undefined",
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -277,3 +696,122 @@ func TestEvalErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestFloats(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"1.5", "1.5"},
+		{"2.0", "2.0"},
+		{"-1.5", "-1.5"},
+		{"1.5 + 1.5", "3.0"},
+		{"1 + 0.5", "1.5"},
+		{"0.5 * 4", "2.0"},
+		{"1 / 4.0", "0.25"},
+		{"7 / 2", "3"},
+		{"1.5 - 2", "-0.5"},
+		{"0.1 + 0.2", "0.30000000000000004"},
+		{"1.0 == 1", "true"},
+		{"1.5 != 1.5", "false"},
+		{"1 < 1.5", "true"},
+		{"2.5 > 3", "false"},
+		{"1000000.0 * 1000000.0 * 1000000000.0", "1e+21"},
+		{"[1.5][0]", "1.5"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			_, program := parser.New(lexer.New(testCase.input)).ParseProgram()
+			actual := Eval(program, object.NewEnvironment())
+
+			assert.Equal(t, testCase.expected, actual.Inspect())
+		})
+	}
+}
+
+func TestFunctionDeclarationName(t *testing.T) {
+	_, program := parser.New(lexer.New("fn add(a, b) { a + b } let sum = add; let f = fn() {}; [sum, f]")).ParseProgram()
+
+	actual := Eval(program, object.NewEnvironment()).(*object.Array)
+
+	assert.Equal(t, "add", actual.Elements[0].(*object.Function).Name)
+	assert.Equal(t, "", actual.Elements[1].(*object.Function).Name)
+}
+
+func TestFunctionInspect(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"fn(a, b) { a + b }", "fn(a, b)"},
+		{"fn add(a, b = 1, ...rest) { a + b } add", "fn add(a, b = 1, ...rest)"},
+		{"[fn() { 1 }]", "[fn()]"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			_, program := parser.New(lexer.New(testCase.input)).ParseProgram()
+			actual := Eval(program, object.NewEnvironment())
+
+			assert.Equal(t, testCase.expected, actual.Inspect())
+		})
+	}
+}
+
+func TestFunctionDetails(t *testing.T) {
+	input := `let offset = 10;
+let unused = 1;
+fn scale(x) {
+	let factor = 2;
+	x * factor + offset + scale(0) + missing
+}
+scale`
+
+	_, program := parser.New(lexer.New(input)).ParseProgram()
+	actual := Eval(program, object.NewEnvironment()).(*object.Function)
+
+	expected := `fn scale(x) {
+	let factor = 2;
+	x * factor + offset + scale(0) + missing
+}
+captured:
+	offset = 10
+	scale = fn scale(x)`
+
+	assert.Equal(t, expected, actual.Details())
+}
+
+func TestFunctionDetailsWithoutSource(t *testing.T) {
+	_, program := parser.New(lexer.New("let a = 1; fn(b) { a + b }")).ParseProgram()
+	function := Eval(program, object.NewEnvironment()).(*object.Function)
+	function.Source = ""
+
+	assert.Equal(t, "fn(b) { (a + b) }\ncaptured:\n\ta = 1", function.Details())
+}
+
+func TestEvalDecodedProgram(t *testing.T) {
+	inputs := []string{
+		"let sum = fn (a, b) { return a + b; }; sum(10, sum(5, 5));",
+		"if (10 == 5) { 5; } else { -(10 * 2) / 4; }",
+		"let a = 7; if (!(a > 5)) { a; } else { true != false };",
+		"5 + true;",
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			_, program := parser.New(lexer.New(input)).ParseProgram()
+
+			data, err := ast.EncodeJSON(program)
+			assert.Nil(t, err)
+
+			decoded, err := ast.DecodeProgramJSON(data)
+			assert.Nil(t, err)
+
+			expected := Eval(program, object.NewEnvironment())
+			actual := Eval(decoded, object.NewEnvironment())
+
+			assert.Equal(t, expected.Inspect(), actual.Inspect())
+		})
+	}
+}
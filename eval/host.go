@@ -0,0 +1,265 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"monkey/object"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Capabilities grant scripts access to the host. The builtins which need a
+// capability are only defined if the host grants it, so scripts of an
+// interpreter without capabilities cannot touch the file system or the
+// process.
+//
+// Paths are allowed if they are one of the listed files or directories or
+// inside of them. Symbolic links are resolved before the check, so they
+// cannot lead out of the allowed directories.
+type Capabilities struct {
+	// Read lists the paths read_file and list_dir may access.
+	Read []string
+
+	// Write lists the paths write_file may write to.
+	Write []string
+
+	// Imports lists the paths import may load modules from, in addition
+	// to the directories of the search path of the loader and the
+	// directory of the script evaluated by EvalFile.
+	Imports []string
+
+	// Env lists the names of the environment variables getenv may read,
+	// "*" allows all of them.
+	Env []string
+
+	// Args are the command line arguments returned by args. If nil, args
+	// is not defined.
+	Args []string
+
+	// Exit defines exit, which aborts the evaluation with an EXIT error
+	// carrying the exit status.
+	Exit bool
+}
+
+// hostBuiltin returns the builtin name if the capabilities of the
+// interpreter grant it. The capabilities are read on the first lookup.
+func (interpreter *Interpreter) hostBuiltin(name string) (*object.Builtin, bool) {
+	if interpreter.granted == nil {
+		interpreter.granted = indexBuiltins(interpreter.Capabilities.builtins())
+	}
+
+	builtin, ok := interpreter.granted[name]
+	return builtin, ok
+}
+
+func (capabilities Capabilities) builtins() []*object.Builtin {
+	granted := []*object.Builtin{}
+
+	if len(capabilities.Read) > 0 {
+		granted = append(granted,
+			&object.Builtin{Name: "read_file", Fn: capabilities.readFile},
+			&object.Builtin{Name: "list_dir", Fn: capabilities.listDir},
+		)
+	}
+	if len(capabilities.Write) > 0 {
+		granted = append(granted, &object.Builtin{Name: "write_file", Fn: capabilities.writeFile})
+	}
+	if len(capabilities.Env) > 0 {
+		granted = append(granted, &object.Builtin{Name: "getenv", Fn: capabilities.getenv})
+	}
+	if capabilities.Args != nil {
+		granted = append(granted, &object.Builtin{Name: "args", Fn: capabilities.args})
+	}
+	if capabilities.Exit {
+		granted = append(granted, &object.Builtin{Name: "exit", Fn: builtinExit})
+	}
+
+	return granted
+}
+
+// readFile returns the contents of a file as string. It reserves the size of
+// the file before reading it.
+func (capabilities Capabilities) readFile(runtime object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("read_file", arguments, 1, object.STRING_OBJECT); err != nil {
+		return err
+	}
+
+	path, err := allowedPath("read_file", stringValue(arguments[0]), capabilities.Read)
+	if err != nil {
+		return err
+	}
+
+	file, openErr := os.Open(path)
+	if openErr != nil {
+		return newError("read_file: %s", openErr)
+	}
+	defer file.Close()
+
+	info, statErr := file.Stat()
+	if statErr != nil {
+		return newError("read_file: %s", statErr)
+	}
+	if err := reserveString(runtime, "read_file", info.Size()); err != nil {
+		return err
+	}
+
+	// Files like those of /proc report no size, they are read up to the
+	// maximum length and reserved once read
+	contents, readErr := io.ReadAll(io.LimitReader(file, maxLength+1))
+	if readErr != nil {
+		return newError("read_file: %s", readErr)
+	}
+	if int64(len(contents)) > info.Size() {
+		if err := reserveString(runtime, "read_file", int64(len(contents))); err != nil {
+			return err
+		}
+	}
+
+	return &object.String{Value: string(contents)}
+}
+
+// listDir returns the names of the entries of a directory in order.
+func (capabilities Capabilities) listDir(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("list_dir", arguments, 1, object.STRING_OBJECT); err != nil {
+		return err
+	}
+
+	path, err := allowedPath("list_dir", stringValue(arguments[0]), capabilities.Read)
+	if err != nil {
+		return err
+	}
+
+	entries, readErr := os.ReadDir(path)
+	if readErr != nil {
+		return newError("list_dir: %s", readErr)
+	}
+
+	names := make([]object.Object, len(entries))
+	for index, entry := range entries {
+		names[index] = &object.String{Value: entry.Name()}
+	}
+
+	return &object.Array{Elements: names}
+}
+
+// writeFile replaces the contents of a file by a string. It creates the
+// file, but not its directory.
+func (capabilities Capabilities) writeFile(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("write_file", arguments, 2, object.STRING_OBJECT, object.STRING_OBJECT); err != nil {
+		return err
+	}
+
+	path, err := allowedPath("write_file", stringValue(arguments[0]), capabilities.Write)
+	if err != nil {
+		return err
+	}
+
+	if writeErr := os.WriteFile(path, []byte(stringValue(arguments[1])), 0o644); writeErr != nil {
+		return newError("write_file: %s", writeErr)
+	}
+
+	return NULL
+}
+
+// getenv returns the value of an environment variable, or null if it is not
+// set.
+func (capabilities Capabilities) getenv(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("getenv", arguments, 1, object.STRING_OBJECT); err != nil {
+		return err
+	}
+
+	name := stringValue(arguments[0])
+	if !slices.Contains(capabilities.Env, "*") && !slices.Contains(capabilities.Env, name) {
+		return newError("getenv: access to %s denied", name)
+	}
+
+	if value, ok := os.LookupEnv(name); ok {
+		return &object.String{Value: value}
+	}
+	return NULL
+}
+
+func (capabilities Capabilities) args(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArgumentCount("args", arguments, 0, 0); err != nil {
+		return err
+	}
+
+	elements := make([]object.Object, len(capabilities.Args))
+	for index, arg := range capabilities.Args {
+		elements[index] = &object.String{Value: arg}
+	}
+
+	return &object.Array{Elements: elements}
+}
+
+// builtinExit aborts the evaluation with an exit status, which defaults
+// to 0.
+func builtinExit(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("exit", arguments, 0, object.INTEGER_OBJECT); err != nil {
+		return err
+	}
+
+	code := 0
+	if len(arguments) > 0 {
+		code = int(arguments[0].(*object.Integer).Value)
+	}
+
+	return &object.Error{Message: fmt.Sprintf("exit status %d", code), Kind: object.EXIT_ERROR, Code: code}
+}
+
+// allowedPath returns the absolute path of path with symbolic links
+// resolved if it is inside of one of the allowed paths.
+func allowedPath(name string, path string, allowed []string) (string, *object.Error) {
+	resolved, err := resolvePath(path)
+	if err != nil {
+		return "", newError("%s: %s", name, err)
+	}
+
+	for _, root := range allowed {
+		resolvedRoot, err := resolvePath(root)
+		if err != nil {
+			continue
+		}
+
+		relative, err := filepath.Rel(resolvedRoot, resolved)
+		if err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+
+	return "", newError("%s: access to %s denied", name, path)
+}
+
+// resolvePath returns the absolute path of path with all symbolic links
+// resolved. Only the links of the part of the path which exists can be
+// resolved, the rest is appended as it is. Links to missing files fail, as
+// creating the file would follow them.
+func resolvePath(path string) (string, error) {
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	missing := ""
+	for {
+		resolved, err := filepath.EvalSymlinks(absolute)
+		if err == nil {
+			return filepath.Join(resolved, missing), nil
+		}
+
+		parent := filepath.Dir(absolute)
+		if !errors.Is(err, fs.ErrNotExist) || parent == absolute {
+			return "", err
+		}
+		if _, lstatErr := os.Lstat(absolute); lstatErr == nil {
+			return "", fmt.Errorf("%s is a link to a missing file", absolute)
+		}
+
+		missing = filepath.Join(filepath.Base(absolute), missing)
+		absolute = parent
+	}
+}
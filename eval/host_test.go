@@ -0,0 +1,127 @@
+package eval
+
+import (
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func evalWithCapabilities(capabilities Capabilities, input string) object.Object {
+	_, program := parser.New(lexer.New(input)).ParseProgram()
+	interpreter := &Interpreter{Capabilities: capabilities}
+	return interpreter.Eval(program, object.NewEnvironment())
+}
+
+func TestCapabilitiesDefineBuiltins(t *testing.T) {
+	names := []string{"read_file", "list_dir", "write_file", "getenv", "args", "exit"}
+
+	for _, name := range names {
+		actual := evalWithCapabilities(Capabilities{}, name)
+		assert.Equal(t, "Error: identifier not found "+name, actual.Inspect())
+	}
+
+	all := Capabilities{Read: []string{"."}, Write: []string{"."}, Env: []string{"*"}, Args: []string{}, Exit: true}
+	for _, name := range names {
+		actual := evalWithCapabilities(all, name)
+		assert.Equal(t, "builtin "+name, actual.Inspect())
+	}
+}
+
+func TestFileBuiltins(t *testing.T) {
+	dir := t.TempDir()
+	sandbox := filepath.Join(dir, "sandbox")
+	writeFiles(t, sandbox, map[string]string{"a.txt": "hello", "sub/b.txt": "b", "out/.keep": ""})
+	writeFiles(t, dir, map[string]string{"secret.txt": "secret"})
+	assert.Nil(t, os.Symlink(filepath.Join(dir, "secret.txt"), filepath.Join(sandbox, "link.txt")))
+	assert.Nil(t, os.Symlink(filepath.Join(dir, "pwned.txt"), filepath.Join(sandbox, "out/dangling.txt")))
+
+	capabilities := Capabilities{
+		Read:  []string{sandbox},
+		Write: []string{filepath.Join(sandbox, "out")},
+	}
+
+	// $DIR is replaced by the temporary directory
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{`read_file("$DIR/sandbox/a.txt")`, "hello"},
+		{`read_file("$DIR/sandbox/sub/../a.txt")`, "hello"},
+		{`list_dir("$DIR/sandbox")`, "[a.txt, link.txt, out, sub]"},
+		{`list_dir("$DIR/sandbox/sub")`, "[b.txt]"},
+		{`write_file("$DIR/sandbox/out/c.txt", "c"); read_file("$DIR/sandbox/out/c.txt")`, "c"},
+		{`read_file("$DIR/secret.txt")`, "Error: read_file: access to $DIR/secret.txt denied"},
+		{`read_file("$DIR/sandbox/../secret.txt")`, "Error: read_file: access to $DIR/sandbox/../secret.txt denied"},
+		{`read_file("$DIR/sandbox/link.txt")`, "Error: read_file: access to $DIR/sandbox/link.txt denied"},
+		{`list_dir("$DIR")`, "Error: list_dir: access to $DIR denied"},
+		{`write_file("$DIR/sandbox/a.txt", "x")`, "Error: write_file: access to $DIR/sandbox/a.txt denied"},
+		{`write_file("$DIR/sandbox/out/missing/c.txt", "x")`, "Error: write_file: open $DIR/sandbox/out/missing/c.txt: no such file or directory"},
+		{`write_file("$DIR/sandbox/out/dangling.txt", "x")`, "Error: write_file: $DIR/sandbox/out/dangling.txt is a link to a missing file"},
+		{`write_file("$DIR/sandbox/out/dangling.txt/c.txt", "x")`, "Error: write_file: $DIR/sandbox/out/dangling.txt is a link to a missing file"},
+		{`read_file("$DIR/sandbox/missing.txt")`, "Error: read_file: open $DIR/sandbox/missing.txt: no such file or directory"},
+		{`read_file(1)`, "Error: read_file: argument 1 must be STRING, got INTEGER"},
+		{`write_file("$DIR/sandbox/out/c.txt")`, "Error: write_file: expected 2 arguments got only 1"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			input := strings.ReplaceAll(testCase.input, "$DIR", dir)
+			expected := strings.ReplaceAll(testCase.expected, "$DIR", dir)
+
+			actual := evalWithCapabilities(capabilities, input)
+
+			assert.Equal(t, expected, actual.Inspect())
+		})
+	}
+
+	assert.NoFileExists(t, filepath.Join(dir, "pwned.txt"))
+}
+
+func TestProcessBuiltins(t *testing.T) {
+	t.Setenv("MONKEY_ALLOWED", "yes")
+	t.Setenv("MONKEY_SECRET", "no")
+
+	capabilities := Capabilities{
+		Env:  []string{"MONKEY_ALLOWED", "MONKEY_UNSET"},
+		Args: []string{"a", "b"},
+		Exit: true,
+	}
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{`getenv("MONKEY_ALLOWED")`, "yes"},
+		{`getenv("MONKEY_UNSET")`, "null"},
+		{`getenv("MONKEY_SECRET")`, "Error: getenv: access to MONKEY_SECRET denied"},
+		{"args()", "[a, b]"},
+		{"args(1)", "Error: args: expected 0 arguments got 1"},
+		{"exit(3); 1", "Error: exit status 3"},
+		{"fn() { map([1], fn(x) { exit() }); 1 }()", "Error: exit status 0"},
+		{`exit("a")`, "Error: exit: argument 1 must be INTEGER, got STRING"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			actual := evalWithCapabilities(capabilities, testCase.input)
+
+			assert.Equal(t, testCase.expected, actual.Inspect())
+		})
+	}
+
+	all := evalWithCapabilities(Capabilities{Env: []string{"*"}}, `getenv("MONKEY_SECRET")`)
+	assert.Equal(t, "no", all.Inspect())
+}
+
+func TestExitError(t *testing.T) {
+	actual := evalWithCapabilities(Capabilities{Exit: true}, "exit(2)").(*object.Error)
+
+	assert.Equal(t, object.EXIT_ERROR, actual.Kind)
+	assert.Equal(t, 2, actual.Code)
+}
@@ -0,0 +1,114 @@
+package eval
+
+import (
+	"context"
+	"math/rand"
+	"monkey/ast"
+	"monkey/object"
+	"sync/atomic"
+	"time"
+)
+
+// An Interpreter evaluates syntax trees. Its fields configure the language
+// for a host, the zero value is ready to use with the default behavior.
+//
+// An Interpreter runs one evaluation at a time. Evaluations on several
+// goroutines use an Interpreter each and share their common bindings in a
+// frozen environment, see object.Environment.Freeze.
+type Interpreter struct {
+	// Truthy decides which values count as true in conditions and for the
+	// ! operator. If nil, DefaultTruthy is used.
+	Truthy func(value object.Object) bool
+
+	// Modules loads the modules of import statements. If nil, a loader
+	// without search path is created on the first import.
+	Modules *Loader
+
+	// Random is the source of math.random and math.random_int. Hosts seed
+	// it to make scripts reproducible. If nil, a source seeded with the
+	// current time is created on first use.
+	Random *rand.Rand
+
+	// Capabilities grant scripts access to the file system and the
+	// process. They must not change once the evaluation started.
+	Capabilities Capabilities
+
+	// Limits bound the resources of every evaluation. If the zero value,
+	// only the depth of calls is limited.
+	Limits Limits
+
+	// ErrorValues makes failing builtins return their errors as values,
+	// which scripts check with is_error, instead of failing the evaluation.
+	// Errors of the functions a builtin calls back and errors which abort
+	// the evaluation, like exceeded limits, still fail it.
+	ErrorValues bool
+
+	granted map[string]*object.Builtin // builtins of the capabilities by name
+
+	running    bool                       // whether an evaluation is running
+	evaluation atomic.Pointer[evaluation] // the running or last evaluation
+	depth      int                        // nested calls of the running evaluation
+	loading    []string                   // scripts being evaluated, the innermost last
+	script     string                     // file evaluated by EvalFile, empty for Eval
+}
+
+// Eval evaluates node in env with the default configuration.
+func Eval(node ast.Node, env *object.Environment) object.Object {
+	interpreter := &Interpreter{}
+	return interpreter.Eval(node, env)
+}
+
+// EvalContext evaluates node in env with the default configuration until
+// ctx is done.
+func EvalContext(ctx context.Context, node ast.Node, env *object.Environment) object.Object {
+	interpreter := &Interpreter{}
+	return interpreter.EvalContext(ctx, node, env)
+}
+
+// Call calls a function or builtin with arguments, e.g. a callback passed to
+// a builtin or a function a host got from a script.
+func (interpreter *Interpreter) Call(function object.Object, arguments ...object.Object) object.Object {
+	return interpreter.run(context.Background(), func() object.Object {
+		return interpreter.applyFunction(function, arguments)
+	})
+}
+
+// Rand returns the source of random numbers of the interpreter.
+func (interpreter *Interpreter) Rand() *rand.Rand {
+	if interpreter.Random == nil {
+		interpreter.Random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return interpreter.Random
+}
+
+// The truthiness of values differs between the available functions:
+//
+//	value          DefaultTruthy  LooseTruthy
+//	false, null    false          false
+//	0, 0.0         true           false
+//	"", [], {}     true           false
+//	anything else  true           true
+
+// DefaultTruthy treats false and null as false and everything else as true.
+func DefaultTruthy(value object.Object) bool {
+	return value != FALSE && value != NULL
+}
+
+// LooseTruthy additionally treats zero and empty strings, arrays and hashes
+// as false.
+func LooseTruthy(value object.Object) bool {
+	switch value := value.(type) {
+	case *object.Integer:
+		return value.Value != 0
+	case *object.Float:
+		return value.Value != 0
+	case *object.String:
+		return value.Value != ""
+	case *object.Array:
+		return len(value.Elements) > 0
+	case *object.Hash:
+		return len(value.Keys) > 0
+	default:
+		return DefaultTruthy(value)
+	}
+}
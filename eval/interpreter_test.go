@@ -0,0 +1,136 @@
+package eval
+
+import (
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruthiness(t *testing.T) {
+	testCases := []struct {
+		input  string
+		truthy bool
+		loose  bool
+	}{
+		{"true", true, true},
+		{"false", false, false},
+		{"null", false, false},
+		{"if (false) { 1 }", false, false},
+		{"0", true, false},
+		{"1", true, true},
+		{"-1", true, true},
+		{`""`, true, false},
+		{`"a"`, true, true},
+		{"[]", true, false},
+		{"[0]", true, true},
+		{"{}", true, false},
+		{`{"a": null}`, true, true},
+		{"fn() {}", true, true},
+	}
+
+	check := func(t *testing.T, interpreter *Interpreter, input string, expected bool) {
+		for _, source := range []string{
+			"if (" + input + ") { true } else { false }",
+			"!!(" + input + ")",
+		} {
+			_, program := parser.New(lexer.New(source)).ParseProgram()
+			actual := interpreter.Eval(program, object.NewEnvironment())
+
+			assert.Equal(t, nativeBoolToBooleanObject(expected), actual, source)
+		}
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			check(t, &Interpreter{}, testCase.input, testCase.truthy)
+			check(t, &Interpreter{Truthy: DefaultTruthy}, testCase.input, testCase.truthy)
+			check(t, &Interpreter{Truthy: LooseTruthy}, testCase.input, testCase.loose)
+		})
+	}
+}
+
+func TestCustomTruthiness(t *testing.T) {
+	// Only true is true
+	interpreter := &Interpreter{
+		Truthy: func(value object.Object) bool { return value == TRUE },
+	}
+
+	_, program := parser.New(lexer.New(`[if (1) { "yes" } else { "no" }, !1, !true]`)).ParseProgram()
+	actual := interpreter.Eval(program, object.NewEnvironment())
+
+	assert.Equal(t, "[no, true, false]", actual.Inspect())
+}
+
+func TestEveryStatementHasAValue(t *testing.T) {
+	inputs := []string{
+		"",
+		"let a = 1;",
+		"fn f() {}",
+		"if (false) { 1 }",
+		"if (true) {}",
+		"fn() {}()",
+		";;",
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			_, program := parser.New(lexer.New(input)).ParseProgram()
+			actual := Eval(program, object.NewEnvironment())
+
+			assert.Same(t, NULL, actual)
+		})
+	}
+}
+
+func TestFrozenEnvironment(t *testing.T) {
+	prelude := object.NewEnvironment()
+	_, program := parser.New(lexer.New(`
+		let greeting = "hello";
+		fn greet(name) { let message = greeting + " " + name; message }
+		fn fib(n) { n < 2 ? n : fib(n - 1) + fib(n - 2) }
+	`)).ParseProgram()
+	Eval(program, prelude)
+	prelude.Freeze()
+
+	assert.True(t, prelude.Frozen())
+	assert.Panics(t, func() { prelude.Set("greeting", NULL) })
+
+	_, program = parser.New(lexer.New(`let greeting = "hi"; [greeting, greet("you"), fib(15)]`)).ParseProgram()
+
+	var wait sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+
+			interpreter := &Interpreter{}
+			for j := 0; j < 10; j++ {
+				actual := interpreter.Eval(program, prelude)
+				assert.Equal(t, "[hi, hello you, 610]", actual.Inspect())
+			}
+		}()
+	}
+	wait.Wait()
+
+	greeting, _ := prelude.Get("greeting")
+	assert.Equal(t, "hello", greeting.Inspect())
+}
+
+func TestFrozenEnvironmentLayers(t *testing.T) {
+	prelude := object.NewEnvironment()
+	prelude.Set("a", &object.Integer{Value: 1})
+	prelude.Freeze()
+
+	env := object.NewEnclosedEnvironment(prelude)
+	_, program := parser.New(lexer.New("let b = a + 1; b")).ParseProgram()
+	actual := Eval(program, env)
+
+	assert.Equal(t, "2", actual.Inspect())
+	assert.False(t, env.Frozen())
+	b, _ := env.Get("b")
+	assert.Equal(t, "2", b.Inspect())
+}
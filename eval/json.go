@@ -0,0 +1,273 @@
+package eval
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"monkey/object"
+	"strings"
+)
+
+// JSON objects, arrays, strings, booleans and null are hashes, arrays,
+// strings, booleans and null in Monkey. Numbers without fraction and
+// exponent which fit into 64 bits are integers, all others floats.
+var jsonModule = newModule("json", []*object.Builtin{
+	{Name: "json.parse", Fn: builtinParseJSON},
+	{Name: "json.stringify", Fn: builtinStringifyJSON},
+}, nil)
+
+// builtinParseJSON converts a JSON text to a value. The pairs of objects
+// keep the order of the text.
+func builtinParseJSON(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("json.parse", arguments, 1, object.STRING_OBJECT); err != nil {
+		return err
+	}
+
+	text := []byte(stringValue(arguments[0]))
+
+	// Unmarshal reports syntax errors more clearly than the tokens of a
+	// decoder, which keep the order of pairs
+	var raw json.RawMessage
+	if err := json.Unmarshal(text, &raw); err != nil {
+		return newError("json.parse: %s", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	value, err := decodeJSON(decoder)
+	if err != nil {
+		return newError("json.parse: %s", err)
+	}
+	return value
+}
+
+func decodeJSON(decoder *json.Decoder) (object.Object, error) {
+	tok, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok := tok.(type) {
+	case nil:
+		return NULL, nil
+	case bool:
+		return nativeBoolToBooleanObject(tok), nil
+	case string:
+		return &object.String{Value: tok}, nil
+	case json.Number:
+		if integer, err := tok.Int64(); err == nil {
+			return &object.Integer{Value: integer}, nil
+		}
+		float, err := tok.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return &object.Float{Value: float}, nil
+	}
+
+	elements := []object.Object{}
+	hash := object.NewHash()
+
+	for decoder.More() {
+		var key string
+		if tok == json.Delim('{') {
+			// The decoder only returns strings as keys
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key = keyToken.(string)
+		}
+
+		value, err := decodeJSON(decoder)
+		if err != nil {
+			return nil, err
+		}
+
+		if tok == json.Delim('{') {
+			hash.Set(&object.String{Value: key}, value)
+		} else {
+			elements = append(elements, value)
+		}
+	}
+
+	// The closing delimiter
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	if tok == json.Delim('{') {
+		return hash, nil
+	}
+	return &object.Array{Elements: elements}, nil
+}
+
+// maxIndent is the longest indent of json.stringify, like in JavaScript.
+const maxIndent = 10
+
+// builtinStringifyJSON converts a value to a JSON text. Only hashes with
+// string keys can be converted. An optional indent, a number of spaces or
+// a string, puts every element and pair on a line of its own.
+func builtinStringifyJSON(runtime object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArgumentCount("json.stringify", arguments, 1, 2); err != nil {
+		return err
+	}
+
+	indent := ""
+	if len(arguments) > 1 {
+		switch argument := arguments[1].(type) {
+		case *object.Integer:
+			if argument.Value < 0 {
+				return newError("json.stringify: negative indent %d", argument.Value)
+			}
+			if argument.Value > maxIndent {
+				return newError("json.stringify: indent %d exceeds the maximum of %d", argument.Value, maxIndent)
+			}
+			indent = strings.Repeat(" ", int(argument.Value))
+		case *object.String:
+			if len(argument.Value) > maxIndent {
+				return newError("json.stringify: indent of %d bytes exceeds the maximum of %d", len(argument.Value), maxIndent)
+			}
+			indent = argument.Value
+		default:
+			return argumentTypeError("json.stringify", 1, argument, object.INTEGER_OBJECT, object.STRING_OBJECT)
+		}
+	}
+
+	encoder := &jsonEncoder{runtime: runtime, indent: indent}
+	if err := encoder.encode(arguments[0]); err != nil {
+		return err
+	}
+
+	return &object.String{Value: encoder.out.String()}
+}
+
+// A jsonEncoder writes values as JSON text. The text of values which share
+// elements can be far larger than the values, so it reserves the text as it
+// grows.
+type jsonEncoder struct {
+	runtime object.Runtime
+	out     bytes.Buffer
+	indent  string // puts elements on lines of their own unless empty
+	depth   int    // nested arrays and hashes
+}
+
+func (encoder *jsonEncoder) encode(value object.Object) *object.Error {
+	if err := encoder.reserve(); err != nil {
+		return err
+	}
+
+	out := &encoder.out
+
+	switch value := value.(type) {
+	case *object.Null:
+		out.WriteString("null")
+
+	case *object.Boolean, *object.Integer:
+		out.WriteString(value.Inspect())
+
+	case *object.Float:
+		if math.IsNaN(value.Value) || math.IsInf(value.Value, 0) {
+			return newError("json.stringify: cannot convert %s", value.Inspect())
+		}
+
+		data, _ := json.Marshal(value.Value)
+		out.Write(data)
+		// Keep the fraction to read the number back as float
+		if !bytes.ContainsAny(data, ".e") {
+			out.WriteString(".0")
+		}
+
+	case *object.String:
+		encodeJSONString(out, value.Value)
+
+	case *object.Array:
+		out.WriteByte('[')
+		encoder.depth++
+		for index, element := range value.Elements {
+			if index > 0 {
+				out.WriteByte(',')
+			}
+			encoder.newline()
+			if err := encoder.encode(element); err != nil {
+				return err
+			}
+		}
+		encoder.depth--
+		if len(value.Elements) > 0 {
+			encoder.newline()
+		}
+		out.WriteByte(']')
+
+	case *object.Hash:
+		out.WriteByte('{')
+		encoder.depth++
+		for index, key := range value.Keys {
+			pair := value.Pairs[key]
+
+			name, ok := pair.Key.(*object.String)
+			if !ok {
+				return newError("json.stringify: cannot convert hash key %s of type %s", pair.Key.Inspect(), pair.Key.Type())
+			}
+
+			if index > 0 {
+				out.WriteByte(',')
+			}
+			encoder.newline()
+			encodeJSONString(out, name.Value)
+			out.WriteByte(':')
+			if encoder.indent != "" {
+				out.WriteByte(' ')
+			}
+			if err := encoder.encode(pair.Value); err != nil {
+				return err
+			}
+		}
+		encoder.depth--
+		if len(value.Keys) > 0 {
+			encoder.newline()
+		}
+		out.WriteByte('}')
+
+	default:
+		return newError("json.stringify: cannot convert %s", value.Type())
+	}
+
+	return nil
+}
+
+// newline starts a line indented by the depth if the text is indented.
+func (encoder *jsonEncoder) newline() {
+	if encoder.indent == "" {
+		return
+	}
+
+	encoder.out.WriteByte('\n')
+	for level := 0; level < encoder.depth; level++ {
+		encoder.out.WriteString(encoder.indent)
+	}
+}
+
+// reserve counts a step and reserves the text written so far.
+func (encoder *jsonEncoder) reserve() *object.Error {
+	if err := encoder.runtime.Step(); err != nil {
+		return err
+	}
+
+	length := encoder.out.Len()
+	if length > maxLength {
+		return newError("json.stringify: text exceeds the maximum length of %d bytes", maxLength)
+	}
+	return encoder.runtime.Reserve(1, int64(stringSize+length))
+}
+
+// encodeJSONString writes str as JSON string without escaping HTML.
+func encodeJSONString(out *bytes.Buffer, str string) {
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(str)
+
+	// Encode ends every value with a newline
+	out.Truncate(out.Len() - 1)
+}
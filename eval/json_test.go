@@ -0,0 +1,50 @@
+package eval
+
+import (
+	"testing"
+)
+
+func TestJSONBuiltins(t *testing.T) {
+	testBuiltins(t, []struct{ input, expected string }{
+		{`json.parse("1")`, "1"},
+		{`json.parse("-1.5")`, "-1.5"},
+		{`json.parse("1e3")`, "1000.0"},
+		{`json.parse("2.0")`, "2.0"},
+		{`json.parse("12345678901234567890")`, "1.2345678901234567e+19"},
+		{`json.parse("\"a\\u00e4\"")`, "aä"},
+		{`json.parse(" [true, false, null] ")`, "[true, false, null]"},
+		{`json.parse("{\"b\": 1, \"a\": {\"c\": []}}")`, "{b: 1, a: {c: []}}"},
+		{`json.parse("{\"a\": 1, \"a\": 2}")`, "{a: 2}"},
+		{`json.parse("{\"name\": \"Ada\"}").name`, "Ada"},
+		{`json.stringify(null)`, "null"},
+		{`json.stringify([1, 1.5, 2.0, true, "a"])`, `[1,1.5,2.0,true,"a"]`},
+		{`json.stringify({"b": [1], "a": {}})`, `{"b":[1],"a":{}}`},
+		{`json.stringify("<\"\n\">")`, `"<\"\n\">"`},
+		{`json.stringify({"a": [1, 2]}, 2)`, "{\n  \"a\": [\n    1,\n    2\n  ]\n}"},
+		{`json.stringify([1], "\t")`, "[\n\t1\n]"},
+		{`json.stringify([], 2)`, "[]"},
+		{`json.stringify({"a": 1}, 0)`, `{"a":1}`},
+		{`let text = "{\"a\":[1,2.5,{\"b\":null}]}"; json.stringify(json.parse(text)) == text`, "true"},
+	})
+}
+
+func TestJSONBuiltinErrors(t *testing.T) {
+	testBuiltins(t, []struct{ input, expected string }{
+		{`json.parse(1)`, "Error: json.parse: argument 1 must be STRING, got INTEGER"},
+		{`json.parse("")`, "Error: json.parse: unexpected end of JSON input"},
+		{`json.parse("[1,")`, "Error: json.parse: unexpected end of JSON input"},
+		{`json.parse("[1,]")`, "Error: json.parse: invalid character ']' looking for beginning of value"},
+		{`json.parse("{1: 2}")`, "Error: json.parse: invalid character '1' looking for beginning of object key string"},
+		{`json.parse("1 2")`, "Error: json.parse: invalid character '2' after top-level value"},
+		{`json.stringify(fn() {})`, "Error: json.stringify: cannot convert FUNCTION"},
+		{`json.stringify([len])`, "Error: json.stringify: cannot convert BUILTIN"},
+		{`json.stringify({"a": math})`, "Error: json.stringify: cannot convert MODULE"},
+		{`json.stringify([math.pow(-1, 0.5)])`, "Error: json.stringify: cannot convert NaN"},
+		{`json.stringify({1: 2})`, "Error: json.stringify: cannot convert hash key 1 of type INTEGER"},
+		{`json.stringify(1, -1)`, "Error: json.stringify: negative indent -1"},
+		{`json.stringify([1], 4611686018427387904)`, "Error: json.stringify: indent 4611686018427387904 exceeds the maximum of 10"},
+		{`json.stringify([1], "           ")`, "Error: json.stringify: indent of 11 bytes exceeds the maximum of 10"},
+		{`json.stringify(1, null)`, "Error: json.stringify: argument 2 must be INTEGER or STRING, got NULL"},
+		{`json.stringify()`, "Error: json.stringify: expected at least 1 arguments got only 0"},
+	})
+}
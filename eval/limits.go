@@ -0,0 +1,305 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"monkey/ast"
+	"monkey/object"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultDepth is the limit of nested calls if Limits.Depth is 0. It keeps
+// runaway recursion from overflowing the stack of the host.
+const DefaultDepth = 10000
+
+// Limits bound the resources of an evaluation started by Eval, EvalContext,
+// EvalFile or Call. A limit of 0 means no limit, except for Depth. An
+// evaluation which exceeds a limit is aborted with an error of kind LIMIT.
+type Limits struct {
+	// Steps limits the number of evaluated syntax tree nodes, calls of
+	// builtins and iterations of builtins which loop.
+	Steps int
+
+	// Depth limits the number of nested function calls. If 0,
+	// DefaultDepth is used, if negative there is no limit.
+	Depth int
+
+	// Timeout limits the wall-clock time of the evaluation.
+	Timeout time.Duration
+
+	// Objects limits the number of values created by the evaluation.
+	Objects int
+
+	// Memory limits the approximate number of bytes of the values,
+	// environments and bindings created by the evaluation. Builtins check
+	// it before they create large values. An evaluation which exceeds it is
+	// aborted with an error of kind MEMORY.
+	Memory int64
+}
+
+// Approximate sizes in bytes of what counts towards Limits.Memory, including
+// the overhead of Go for them.
+const (
+	numberSize      = 16
+	stringSize      = 32 // plus one byte per byte of the string
+	arraySize       = 40 // plus elementSize per element
+	elementSize     = 16
+	hashSize        = 80 // plus pairSize per pair
+	pairSize        = 96
+	functionSize    = 64
+	environmentSize = 64 // plus bindingSize per binding
+	bindingSize     = 48 // plus one byte per byte of the name
+	taskSize        = 64
+	channelSize     = 96 // plus elementSize per buffered value
+)
+
+// errTimeout is the cause of the context of an evaluation which ran out of
+// time.
+var errTimeout = errors.New("timeout")
+
+// EvalContext evaluates node in env until ctx is done. If it is, the
+// evaluation is aborted with an error of kind CANCELED. If env is frozen,
+// the names of node are bound in a new environment enclosed by env.
+func (interpreter *Interpreter) EvalContext(ctx context.Context, node ast.Node, env *object.Environment) object.Object {
+	if env.Frozen() {
+		env = object.NewEnclosedEnvironment(env)
+	}
+
+	return interpreter.run(ctx, func() object.Object {
+		return interpreter.eval(node, env)
+	})
+}
+
+// Eval evaluates node in env.
+func (interpreter *Interpreter) Eval(node ast.Node, env *object.Environment) object.Object {
+	return interpreter.EvalContext(context.Background(), node, env)
+}
+
+// An evaluation is the state of a running evaluation, which is shared by
+// the tasks it spawns.
+type evaluation struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	steps     atomic.Int64
+	objects   atomic.Int64
+	allocated atomic.Int64 // bytes, read by Allocated while running
+	aborted   atomic.Pointer[object.Error]
+}
+
+// run starts an evaluation with fresh counters for the limits. Evaluations
+// started while one is running, e.g. by a builtin which calls back into a
+// script, count towards the running one. Tasks spawned by the evaluation
+// are canceled when it ends.
+func (interpreter *Interpreter) run(ctx context.Context, evaluate func() object.Object) object.Object {
+	if interpreter.running {
+		return evaluate()
+	}
+
+	var cancel context.CancelFunc
+	if timeout := interpreter.Limits.Timeout; timeout > 0 {
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, errTimeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	current := &evaluation{ctx: ctx, cancel: cancel}
+	interpreter.evaluation.Store(current)
+	interpreter.running = true
+	interpreter.depth = 0
+	defer func() { interpreter.running = false }()
+
+	result := evaluate()
+
+	// The abort error could have been swallowed on its way up, e.g. by a
+	// pattern which failed to match because of it
+	if aborted := current.aborted.Load(); aborted != nil {
+		return aborted
+	}
+	return result
+}
+
+// current returns the running evaluation.
+func (interpreter *Interpreter) current() *evaluation {
+	return interpreter.evaluation.Load()
+}
+
+// Context returns the context of the running evaluation. Builtins which
+// block return once it is done.
+func (interpreter *Interpreter) Context() context.Context {
+	if current := interpreter.current(); current != nil && interpreter.running {
+		return current.ctx
+	}
+	return context.Background()
+}
+
+// step counts the evaluation of a node. It returns an error once the
+// evaluation is aborted.
+func (interpreter *Interpreter) step() *object.Error {
+	current := interpreter.current()
+	if current == nil {
+		return nil
+	}
+
+	if aborted := current.aborted.Load(); aborted != nil {
+		return aborted
+	}
+
+	steps := current.steps.Add(1)
+	if limit := interpreter.Limits.Steps; limit > 0 && steps > int64(limit) {
+		return interpreter.abort(object.LIMIT_ERROR, "step limit of %d exceeded", limit)
+	}
+
+	return interpreter.checkContext()
+}
+
+// Step counts an iteration of a builtin like a step of the evaluation.
+func (interpreter *Interpreter) Step() *object.Error {
+	return interpreter.step()
+}
+
+// Reserve aborts the evaluation if creating count values of size bytes in
+// total would exceed its limits. It does not count them, allocate does once
+// the builtin returns them.
+func (interpreter *Interpreter) Reserve(count int, size int64) *object.Error {
+	current := interpreter.current()
+	if current == nil {
+		return nil
+	}
+
+	// Subtracts instead of adding, which could overflow
+	if limit := int64(interpreter.Limits.Objects); limit > 0 && int64(count) > limit-current.objects.Load() {
+		return interpreter.abort(object.LIMIT_ERROR, "object limit of %d exceeded", limit)
+	}
+	if limit := interpreter.Limits.Memory; limit > 0 && size > limit-current.allocated.Load() {
+		return interpreter.abort(object.MEMORY_ERROR, "out of memory: quota of %d bytes exceeded", limit)
+	}
+	return nil
+}
+
+// checkContext aborts the evaluation if its context is done.
+func (interpreter *Interpreter) checkContext() *object.Error {
+	ctx := interpreter.current().ctx
+
+	select {
+	case <-ctx.Done():
+		if context.Cause(ctx) == errTimeout {
+			return interpreter.abort(object.LIMIT_ERROR, "timeout of %s exceeded", interpreter.Limits.Timeout)
+		}
+		return interpreter.abort(object.CANCELED_ERROR, "evaluation canceled: %s", ctx.Err())
+	default:
+		return nil
+	}
+}
+
+// enterCall counts a nested function call, leaveCall must be called when it
+// returns.
+func (interpreter *Interpreter) enterCall() *object.Error {
+	interpreter.depth++
+
+	limit := interpreter.Limits.Depth
+	if limit == 0 {
+		limit = DefaultDepth
+	}
+
+	if limit > 0 && interpreter.depth > limit {
+		return interpreter.abort(object.LIMIT_ERROR, "call depth limit of %d exceeded", limit)
+	}
+	return nil
+}
+
+func (interpreter *Interpreter) leaveCall() {
+	interpreter.depth--
+}
+
+// allocate counts the values created by the evaluation. It returns value,
+// or an error if the evaluation exceeds its limits. Arrays and hashes count
+// their elements too, as builtins create them without evaluating any node.
+func (interpreter *Interpreter) allocate(value object.Object) object.Object {
+	count, size := 1, 0
+	switch value := value.(type) {
+	case *object.Null, *object.Boolean, *object.Error, *object.Builtin, *object.Module:
+		// Shared or not created by the evaluation
+		return value
+	case *object.Integer, *object.Float:
+		size = numberSize
+	case *object.String:
+		size = stringSize + len(value.Value)
+	case *object.Array:
+		count += len(value.Elements)
+		size = arraySize + elementSize*len(value.Elements)
+	case *object.Hash:
+		count += len(value.Keys)
+		size = hashSize + pairSize*len(value.Keys)
+	case *object.Function:
+		size = functionSize
+	case *object.Task:
+		size = taskSize
+	case *object.Channel:
+		size = channelSize + elementSize*cap(value.Values)
+	}
+
+	current := interpreter.current()
+	if current == nil {
+		return value
+	}
+
+	objects := current.objects.Add(int64(count))
+	if limit := interpreter.Limits.Objects; limit > 0 && objects > int64(limit) {
+		return interpreter.abort(object.LIMIT_ERROR, "object limit of %d exceeded", limit)
+	}
+
+	if err := interpreter.allocateMemory(size); err != nil {
+		return err
+	}
+	return value
+}
+
+// newEnvironment creates an environment enclosed by outer. If it exceeds the
+// memory limit, the evaluation is aborted at its next step.
+func (interpreter *Interpreter) newEnvironment(outer *object.Environment) *object.Environment {
+	interpreter.allocateMemory(environmentSize)
+	return object.NewEnclosedEnvironment(outer)
+}
+
+// bind binds name to value in env. Like newEnvironment, it aborts the
+// evaluation at its next step if the binding exceeds the memory limit.
+func (interpreter *Interpreter) bind(env *object.Environment, name string, value object.Object) {
+	interpreter.allocateMemory(bindingSize + len(name))
+	env.Set(name, value)
+}
+
+func (interpreter *Interpreter) allocateMemory(size int) *object.Error {
+	current := interpreter.current()
+	if current == nil {
+		return nil
+	}
+
+	allocated := current.allocated.Add(int64(size))
+	if limit := interpreter.Limits.Memory; limit > 0 && allocated > limit {
+		return interpreter.abort(object.MEMORY_ERROR, "out of memory: quota of %d bytes exceeded", limit)
+	}
+	return nil
+}
+
+// Allocated returns the approximate number of bytes allocated by the
+// running or the last evaluation, see Limits.Memory. It is safe to call
+// while the evaluation is running.
+func (interpreter *Interpreter) Allocated() int64 {
+	if current := interpreter.current(); current != nil {
+		return current.allocated.Load()
+	}
+	return 0
+}
+
+// abort stops the evaluation and the tasks it spawned. Every later step
+// fails with the first abort error, so the abort cannot be ignored.
+func (interpreter *Interpreter) abort(kind object.ErrorKind, format string, a ...any) *object.Error {
+	current := interpreter.current()
+	current.aborted.CompareAndSwap(nil, &object.Error{Message: fmt.Sprintf(format, a...), Kind: kind})
+	// Wakes up the tasks which wait
+	current.cancel()
+	return current.aborted.Load()
+}
@@ -0,0 +1,171 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const endlessLoop = "let loop = fn(n) { loop(n) }; loop(1)"
+
+func evalWithLimits(ctx context.Context, limits Limits, input string) object.Object {
+	_, program := parser.New(lexer.New(input)).ParseProgram()
+	interpreter := &Interpreter{Limits: limits}
+	return interpreter.EvalContext(ctx, program, object.NewEnvironment())
+}
+
+func TestLimits(t *testing.T) {
+	testCases := []struct {
+		name     string
+		limits   Limits
+		input    string
+		expected string
+	}{
+		{"steps", Limits{Steps: 100}, endlessLoop, "Error: step limit of 100 exceeded"},
+		{"steps not exceeded", Limits{Steps: 100}, "1 + 2", "3"},
+		{"default depth", Limits{}, endlessLoop, "Error: call depth limit of 10000 exceeded"},
+		{"depth", Limits{Depth: 10}, endlessLoop, "Error: call depth limit of 10 exceeded"},
+		{"depth of builtin callbacks", Limits{Depth: 10}, "let f = fn(x) { map([x], f) }; f(1)", "Error: call depth limit of 10 exceeded"},
+		{"depth not exceeded", Limits{Depth: 3}, "let f = fn(n) { n == 0 ? 0 : f(n - 1) }; f(2)", "0"},
+		{"objects", Limits{Objects: 10}, `let f = fn(s) { f(s + "a") }; f("")`, "Error: object limit of 10 exceeded"},
+		{"objects of builtins", Limits{Objects: 10}, "range(10)", "Error: object limit of 10 exceeded"},
+		{"objects not exceeded", Limits{Objects: 10}, "[1, 2, 3]", "[1, 2, 3]"},
+		{"objects before builtins create them", Limits{Objects: 1000}, "len(range(30000000))", "Error: object limit of 1000 exceeded"},
+		{"steps of builtins", Limits{Steps: 100}, "len(range(1000))", "Error: step limit of 100 exceeded"},
+		{"steps of builtin callbacks", Limits{Steps: 100}, "len(map(range(90), len))", "Error: step limit of 100 exceeded"},
+		{"steps of sort", Limits{Steps: 40}, "let a = [5, 4, 3, 2, 1, 5, 4, 3, 2, 1, 5, 4, 3, 2, 1]; sort(a)", "Error: step limit of 40 exceeded"},
+		{"timeout of builtins", Limits{Timeout: 50 * time.Millisecond}, "len(range(30000000))", "Error: timeout of 50ms exceeded"},
+		{"timeout", Limits{Timeout: time.Millisecond, Depth: -1}, endlessLoop, "Error: timeout of 1ms exceeded"},
+		{"memory", Limits{Memory: 1000}, `let f = fn(s) { f(s + "a") }; f("")`, "Error: out of memory: quota of 1000 bytes exceeded"},
+		{"memory of builtins", Limits{Memory: 1000}, `repeat("a", 1000)`, "Error: out of memory: quota of 1000 bytes exceeded"},
+		{"memory before builtins create it", Limits{Memory: 1 << 20}, `len(repeat("x", 134217728))`, "Error: out of memory: quota of 1048576 bytes exceeded"},
+		{"memory of channels", Limits{Memory: 1 << 20}, "channel(134217728)", "Error: out of memory: quota of 1048576 bytes exceeded"},
+		{"memory of json text", Limits{Memory: 1 << 20}, "let double = fn(a, n) { n == 0 ? a : double([a, a], n - 1) }; len(json.stringify(double([1], 40), 2))", "Error: out of memory: quota of 1048576 bytes exceeded"},
+		{"memory of replace", Limits{Memory: 1 << 20}, `let s = repeat("a", 16000); len(replace(s, "a", s))`, "Error: out of memory: quota of 1048576 bytes exceeded"},
+		{"memory of join", Limits{Memory: 1 << 20}, `let s = repeat("a", 16000); len(join(split(s, ""), s))`, "Error: out of memory: quota of 1048576 bytes exceeded"},
+		{"memory of split", Limits{Memory: 1 << 20}, `len(split(repeat("a", 100000), ""))`, "Error: out of memory: quota of 1048576 bytes exceeded"},
+		{"memory of format", Limits{Memory: 1 << 20}, `let s = repeat("a", 300000); len(format("%s%s%s%s", s, s, s, s))`, "Error: out of memory: quota of 1048576 bytes exceeded"},
+		{"memory of bindings", Limits{Memory: 1000}, "let f = fn(n) { let a = n; let b = n; let c = n; n == 0 ? 0 : f(n - 1) }; f(5)", "Error: out of memory: quota of 1000 bytes exceeded"},
+		{"memory of environments", Limits{Memory: 1000, Objects: 1}, "let f = fn() { f() }; f()", "Error: out of memory: quota of 1000 bytes exceeded"},
+		{"memory not exceeded", Limits{Memory: 1000}, `"abc" + "def"`, "abcdef"},
+		// Failing patterns must not hide the abort
+		{"abort in pattern", Limits{Steps: 5}, "match (1) { 1 + 1 + 1 + 1 => 1, _ => 2 }", "Error: step limit of 5 exceeded"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual := evalWithLimits(context.Background(), testCase.limits, testCase.input)
+
+			assert.Equal(t, testCase.expected, actual.Inspect())
+			if actual, ok := actual.(*object.Error); ok {
+				assert.Contains(t, []object.ErrorKind{object.LIMIT_ERROR, object.MEMORY_ERROR}, actual.Kind)
+			}
+		})
+	}
+}
+
+func TestEvalContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	actual := evalWithLimits(ctx, Limits{Depth: -1}, endlessLoop).(*object.Error)
+
+	assert.Equal(t, "evaluation canceled: context canceled", actual.Message)
+	assert.Equal(t, object.CANCELED_ERROR, actual.Kind)
+}
+
+func TestLimitsApplyToEveryEvaluation(t *testing.T) {
+	_, program := parser.New(lexer.New("let a = 1 + 1; a")).ParseProgram()
+	interpreter := &Interpreter{Limits: Limits{Steps: 10}}
+
+	for i := 0; i < 3; i++ {
+		actual := interpreter.Eval(program, object.NewEnvironment())
+		assert.Equal(t, "2", actual.Inspect())
+	}
+
+	_, loop := parser.New(lexer.New(endlessLoop)).ParseProgram()
+	env := object.NewEnvironment()
+	assert.Equal(t, "Error: step limit of 10 exceeded", interpreter.Eval(loop, env).Inspect())
+
+	// Functions called by the host are limited too
+	loopFunction, _ := env.Get("loop")
+	actual := interpreter.Call(loopFunction, &object.Integer{Value: 1})
+	assert.Equal(t, "Error: step limit of 10 exceeded", actual.Inspect())
+}
+
+func TestAllocated(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected int64
+	}{
+		{"1", numberSize},
+		{`"abc"`, stringSize + 3},
+		{"[1, 2]", 2*numberSize + arraySize + 2*elementSize},
+		{`{"a": true}`, stringSize + 1 + hashSize + pairSize},
+		{"fn(x) { x }(true)", functionSize + environmentSize + environmentSize + bindingSize + 1},
+		{"let abc = 1; let [d, _] = [2, 3];", numberSize + bindingSize + 3 + 2*numberSize + arraySize + 2*elementSize + bindingSize + 1},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			_, program := parser.New(lexer.New(testCase.input)).ParseProgram()
+			interpreter := &Interpreter{}
+
+			interpreter.Eval(program, object.NewEnvironment())
+
+			assert.Equal(t, testCase.expected, interpreter.Allocated())
+		})
+	}
+}
+
+func TestMemoryError(t *testing.T) {
+	actual := evalWithLimits(context.Background(), Limits{Memory: 100}, `"a" + "b" + "c" + "d"`).(*object.Error)
+
+	assert.Equal(t, object.MEMORY_ERROR, actual.Kind)
+}
+
+// Builtins must fail before they create results which exceed the quota, not
+// once they return them.
+func TestMemoryIsReservedByBuiltins(t *testing.T) {
+	inputs := []string{
+		`let s = repeat("a", 16000); replace(s, "a", s)`,
+		`let s = repeat("a", 16000); join(split(s, ""), s)`,
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			var before, after goruntime.MemStats
+			goruntime.ReadMemStats(&before)
+
+			actual := evalWithLimits(context.Background(), Limits{Memory: 1 << 20}, input)
+
+			goruntime.ReadMemStats(&after)
+			assert.Equal(t, "Error: out of memory: quota of 1048576 bytes exceeded", actual.Inspect())
+			assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(16<<20))
+		})
+	}
+}
+
+func TestMemoryOfReadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "large.txt")
+	assert.NoError(t, os.WriteFile(path, make([]byte, 2<<20), 0o644))
+
+	_, program := parser.New(lexer.New(fmt.Sprintf("len(read_file(%q))", path))).ParseProgram()
+	interpreter := &Interpreter{Capabilities: Capabilities{Read: []string{dir}}, Limits: Limits{Memory: 1 << 20}}
+	actual := interpreter.Eval(program, object.NewEnvironment())
+
+	assert.Equal(t, "Error: out of memory: quota of 1048576 bytes exceeded", actual.Inspect())
+}
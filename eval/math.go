@@ -0,0 +1,259 @@
+package eval
+
+import (
+	"math"
+	"monkey/object"
+)
+
+// The math builtins accept integers and floats. Functions which can be
+// exact for integers, like abs, min or floor, return integers for them.
+var mathModule = newModule("math", []*object.Builtin{
+	{Name: "math.abs", Fn: builtinAbs},
+	{Name: "math.min", Fn: builtinMin},
+	{Name: "math.max", Fn: builtinMax},
+	{Name: "math.pow", Fn: builtinPow},
+	{Name: "math.sqrt", Fn: builtinSqrt},
+	{Name: "math.floor", Fn: roundingBuiltin("math.floor", math.Floor)},
+	{Name: "math.ceil", Fn: roundingBuiltin("math.ceil", math.Ceil)},
+	{Name: "math.round", Fn: roundingBuiltin("math.round", math.Round)},
+	{Name: "math.sin", Fn: floatBuiltin("math.sin", math.Sin)},
+	{Name: "math.cos", Fn: floatBuiltin("math.cos", math.Cos)},
+	{Name: "math.tan", Fn: floatBuiltin("math.tan", math.Tan)},
+	{Name: "math.asin", Fn: floatBuiltin("math.asin", math.Asin)},
+	{Name: "math.acos", Fn: floatBuiltin("math.acos", math.Acos)},
+	{Name: "math.atan", Fn: builtinAtan},
+	{Name: "math.exp", Fn: floatBuiltin("math.exp", math.Exp)},
+	{Name: "math.log", Fn: builtinLog},
+	{Name: "math.random", Fn: builtinRandom},
+	{Name: "math.random_int", Fn: builtinRandomInt},
+}, map[string]object.Object{
+	"pi": &object.Float{Value: math.Pi},
+	"e":  &object.Float{Value: math.E},
+})
+
+func builtinAbs(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkNumbers("math.abs", arguments, 1, 1); err != nil {
+		return err
+	}
+
+	if integer, ok := arguments[0].(*object.Integer); ok {
+		if integer.Value == math.MinInt64 {
+			return newError("math.abs: %d is out of the range of INTEGER", integer.Value)
+		}
+		if integer.Value < 0 {
+			return &object.Integer{Value: -integer.Value}
+		}
+		return integer
+	}
+
+	value, _ := floatValue(arguments[0])
+	return &object.Float{Value: math.Abs(value)}
+}
+
+// builtinMin returns the smallest of its arguments. If several are equal,
+// the first one is returned.
+func builtinMin(_ object.Runtime, arguments ...object.Object) object.Object {
+	return pickNumber("math.min", arguments, func(value, best float64) bool { return value < best })
+}
+
+// builtinMax returns the largest of its arguments. If several are equal,
+// the first one is returned.
+func builtinMax(_ object.Runtime, arguments ...object.Object) object.Object {
+	return pickNumber("math.max", arguments, func(value, best float64) bool { return value > best })
+}
+
+func pickNumber(name string, arguments []object.Object, isBetter func(value, best float64) bool) object.Object {
+	if err := checkNumbers(name, arguments, 1, -1); err != nil {
+		return err
+	}
+
+	best := arguments[0]
+	for _, argument := range arguments[1:] {
+		value, _ := floatValue(argument)
+		bestValue, _ := floatValue(best)
+
+		if isBetter(value, bestValue) {
+			best = argument
+		}
+	}
+
+	return best
+}
+
+// builtinPow raises its first argument to the power of the second. The
+// result is an integer if both are integers and the exponent is not
+// negative, integers which overflow are an error.
+func builtinPow(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkNumbers("math.pow", arguments, 2, 2); err != nil {
+		return err
+	}
+
+	base, isInteger := arguments[0].(*object.Integer)
+	exponent, isIntegerExponent := arguments[1].(*object.Integer)
+
+	if isInteger && isIntegerExponent && exponent.Value >= 0 {
+		result, ok := int64(1), true
+		for factor, power := base.Value, exponent.Value; power > 0 && ok; power >>= 1 {
+			if power&1 == 1 {
+				result, ok = multiplyIntegers(result, factor)
+			}
+			// Squares which are not needed must not overflow
+			if power > 1 && ok {
+				factor, ok = multiplyIntegers(factor, factor)
+			}
+		}
+		if !ok {
+			return newError("math.pow: %d to the power of %d is out of the range of INTEGER", base.Value, exponent.Value)
+		}
+		return &object.Integer{Value: result}
+	}
+
+	x, _ := floatValue(arguments[0])
+	y, _ := floatValue(arguments[1])
+	return &object.Float{Value: math.Pow(x, y)}
+}
+
+// multiplyIntegers returns the product of a and b and whether it did not
+// overflow.
+func multiplyIntegers(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+
+	product := a * b
+	if product/b != a || a == -1 && b == math.MinInt64 || b == -1 && a == math.MinInt64 {
+		return product, false
+	}
+	return product, true
+}
+
+func builtinSqrt(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkNumbers("math.sqrt", arguments, 1, 1); err != nil {
+		return err
+	}
+
+	value, _ := floatValue(arguments[0])
+	if value < 0 {
+		return newError("math.sqrt: negative argument %s", arguments[0].Inspect())
+	}
+
+	return &object.Float{Value: math.Sqrt(value)}
+}
+
+func builtinLog(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkNumbers("math.log", arguments, 1, 1); err != nil {
+		return err
+	}
+
+	value, _ := floatValue(arguments[0])
+	if value <= 0 {
+		return newError("math.log: argument %s is not positive", arguments[0].Inspect())
+	}
+
+	return &object.Float{Value: math.Log(value)}
+}
+
+// builtinAtan returns the arc tangent of y, or of y / x with the signs of
+// both to determine the quadrant.
+func builtinAtan(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkNumbers("math.atan", arguments, 1, 2); err != nil {
+		return err
+	}
+
+	y, _ := floatValue(arguments[0])
+	if len(arguments) == 1 {
+		return &object.Float{Value: math.Atan(y)}
+	}
+
+	x, _ := floatValue(arguments[1])
+	return &object.Float{Value: math.Atan2(y, x)}
+}
+
+// roundingBuiltin returns a builtin which rounds a float to an integer with
+// round. Integers are returned as they are.
+func roundingBuiltin(name string, round func(float64) float64) object.BuiltinFunction {
+	return func(_ object.Runtime, arguments ...object.Object) object.Object {
+		if err := checkNumbers(name, arguments, 1, 1); err != nil {
+			return err
+		}
+
+		if integer, ok := arguments[0].(*object.Integer); ok {
+			return integer
+		}
+
+		value := round(arguments[0].(*object.Float).Value)
+		// 2^63 is the first float above the largest integer
+		if math.IsNaN(value) || value < math.MinInt64 || value >= math.MaxInt64 {
+			return newError("%s: %s is out of the range of INTEGER", name, arguments[0].Inspect())
+		}
+
+		return &object.Integer{Value: int64(value)}
+	}
+}
+
+// floatBuiltin returns a builtin which applies function to one number.
+func floatBuiltin(name string, function func(float64) float64) object.BuiltinFunction {
+	return func(_ object.Runtime, arguments ...object.Object) object.Object {
+		if err := checkNumbers(name, arguments, 1, 1); err != nil {
+			return err
+		}
+
+		value, _ := floatValue(arguments[0])
+		return &object.Float{Value: function(value)}
+	}
+}
+
+// builtinRandom returns a float in [0.0, 1.0) from the random source of the
+// interpreter.
+func builtinRandom(runtime object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArgumentCount("math.random", arguments, 0, 0); err != nil {
+		return err
+	}
+	return &object.Float{Value: runtime.Rand().Float64()}
+}
+
+// builtinRandomInt returns an integer from start, which defaults to 0, up to
+// but not including end.
+func builtinRandomInt(runtime object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("math.random_int", arguments, 1, object.INTEGER_OBJECT, object.INTEGER_OBJECT); err != nil {
+		return err
+	}
+
+	start, end := int64(0), arguments[0].(*object.Integer).Value
+	if len(arguments) > 1 {
+		start, end = end, arguments[1].(*object.Integer).Value
+	}
+
+	if end <= start {
+		return newError("math.random_int: empty range %d..%d", start, end)
+	}
+
+	// The range can be larger than the largest integer
+	span := uint64(end) - uint64(start)
+	if span <= math.MaxInt64 {
+		return &object.Integer{Value: start + runtime.Rand().Int63n(int64(span))}
+	}
+
+	for {
+		// More than half of the values are in the range
+		if offset := runtime.Rand().Uint64(); offset < span {
+			return &object.Integer{Value: start + int64(offset)}
+		}
+	}
+}
+
+// checkNumbers reports an error unless the number of arguments is within
+// required and maximum and all of them are integers or floats.
+func checkNumbers(name string, arguments []object.Object, required, maximum int) *object.Error {
+	if err := checkArgumentCount(name, arguments, required, maximum); err != nil {
+		return err
+	}
+
+	for index, argument := range arguments {
+		if !isNumber(argument) {
+			return argumentTypeError(name, index, argument, object.INTEGER_OBJECT, object.FLOAT_OBJECT)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package eval
+
+import (
+	"math/rand"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMathBuiltins(t *testing.T) {
+	testBuiltins(t, []struct{ input, expected string }{
+		{"math", "module math {abs, min, max, pow, sqrt, floor, ceil, round, sin, cos, tan, asin, acos, atan, exp, log, random, random_int, e, pi}"},
+		{"math.abs", "builtin math.abs"},
+		{"let math = 1; math", "1"},
+		{"math.abs(-3)", "3"},
+		{"math.abs(-2.5)", "2.5"},
+		{"math.abs(-9223372036854775807)", "9223372036854775807"},
+		{"math.min(3, 1.5, 2)", "1.5"},
+		{"math.min(1, 1.0)", "1"},
+		{"math.max(3, 1.5, 4)", "4"},
+		{"math.max(-1)", "-1"},
+		{"math.pow(2, 10)", "1024"},
+		{"math.pow(3, 0)", "1"},
+		{"math.pow(2, -1)", "0.5"},
+		{"math.pow(4, 0.5)", "2.0"},
+		{"math.pow(2, 62)", "4611686018427387904"},
+		{"math.pow(-2, 63)", "-9223372036854775808"},
+		{"math.pow(-1, 9223372036854775807)", "-1"},
+		{"math.pow(0, 9223372036854775807)", "0"},
+		{"math.sqrt(16)", "4.0"},
+		{"math.sqrt(2.25)", "1.5"},
+		{"math.floor(2.7)", "2"},
+		{"math.floor(-2.5)", "-3"},
+		{"math.floor(5)", "5"},
+		{"math.ceil(2.1)", "3"},
+		{"math.round(2.5)", "3"},
+		{"math.round(-2.4)", "-2"},
+		{"math.sin(0)", "0.0"},
+		{"math.cos(math.pi)", "-1.0"},
+		{"math.round(math.tan(math.pi / 4) * 1000)", "1000"},
+		{"math.atan(1, 1) * 4 == math.pi", "true"},
+		{"math.atan(-1, -1) < 0", "true"},
+		{"math.asin(1) * 2 == math.pi", "true"},
+		{"math.acos(1)", "0.0"},
+		{"math.atan(0)", "0.0"},
+		{"math.exp(0)", "1.0"},
+		{"math.log(math.e)", "1.0"},
+		{"math.pi", "3.141592653589793"},
+		{"sort([2, 1.5, 3])", "[1.5, 2, 3]"},
+	})
+}
+
+func TestMathBuiltinErrors(t *testing.T) {
+	testBuiltins(t, []struct{ input, expected string }{
+		{"math.tau", "Error: math does not export tau"},
+		{`math.abs("a")`, "Error: math.abs: argument 1 must be INTEGER or FLOAT, got STRING"},
+		{"math.min()", "Error: math.min: expected at least 1 arguments got only 0"},
+		{"math.max(1, null)", "Error: math.max: argument 2 must be INTEGER or FLOAT, got NULL"},
+		{"math.pow(2)", "Error: math.pow: expected 2 arguments got only 1"},
+		{"math.sqrt(-1)", "Error: math.sqrt: negative argument -1"},
+		{"math.log(0)", "Error: math.log: argument 0 is not positive"},
+		{"math.floor(1.0 * math.pow(10, 30.0))", "Error: math.floor: 1e+30 is out of the range of INTEGER"},
+		{"math.random(1)", "Error: math.random: expected 0 arguments got 1"},
+		{"math.random_int(0)", "Error: math.random_int: empty range 0..0"},
+		{"math.pow(10, 30)", "Error: math.pow: 10 to the power of 30 is out of the range of INTEGER"},
+		{"math.pow(2, 63)", "Error: math.pow: 2 to the power of 63 is out of the range of INTEGER"},
+		{"math.pow(-3, 41)", "Error: math.pow: -3 to the power of 41 is out of the range of INTEGER"},
+		{"math.abs(-9223372036854775807 - 1)", "Error: math.abs: -9223372036854775808 is out of the range of INTEGER"},
+		{"math.random_int(1.5)", "Error: math.random_int: argument 1 must be INTEGER, got FLOAT"},
+	})
+}
+
+func TestRandomIsSeedable(t *testing.T) {
+	input := "[math.random(), math.random_int(100), math.random_int(-5, 5)]"
+
+	run := func(interpreter *Interpreter) string {
+		_, program := parser.New(lexer.New(input)).ParseProgram()
+		return interpreter.Eval(program, object.NewEnvironment()).Inspect()
+	}
+
+	first := run(&Interpreter{Random: rand.New(rand.NewSource(1))})
+	second := run(&Interpreter{Random: rand.New(rand.NewSource(1))})
+	unseeded := run(&Interpreter{})
+
+	assert.Equal(t, first, second)
+	assert.NotContains(t, unseeded, "Error")
+}
+
+func TestRandomRanges(t *testing.T) {
+	_, program := parser.New(lexer.New(`
+		let floats = map(range(100), fn(_) { math.random() });
+		let integers = map(range(100), fn(_) { math.random_int(-2, 3) });
+		let wide = map(range(100), fn(_) { math.random_int(-5, 9223372036854775807) });
+		[
+			all(floats, fn(x) { x < 0 ? false : x < 1 }),
+			all(integers, fn(x) { x > -3 ? x < 3 : false }),
+			all(range(-2, 3), fn(x) { any(integers, fn(y) { x == y }) }),
+			all(wide, fn(x) { x > -6 }),
+			math.random_int(-9223372036854775807 - 1, 9223372036854775807) < 9223372036854775807
+		]
+	`)).ParseProgram()
+
+	actual := Eval(program, object.NewEnvironment())
+
+	assert.Equal(t, "[true, true, true, true, true]", actual.Inspect())
+}
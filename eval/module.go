@@ -0,0 +1,229 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// A Loader finds, evaluates and caches the modules imported by scripts.
+// Import paths starting with ./ or ../ are relative to the directory of the
+// importing script, or to the working directory outside of a script.
+// Absolute paths are used as they are and all other paths are looked up in
+// the directories of SearchPath in order.
+//
+// Scripts can only import the modules allowed by the capabilities of the
+// interpreter, see Capabilities.Imports.
+//
+// Every module is evaluated once per loader in an environment of its own.
+// All importers share its namespace. Interpreters running at the same time
+// can share a loader, if they import a module at the same time, it could be
+// evaluated by each of them, but all of them get the same namespace.
+type Loader struct {
+	SearchPath []string
+
+	mutex   sync.Mutex
+	modules map[string]*object.Module // evaluated modules by absolute path
+}
+
+// NewLoader returns a loader which looks up imports in the directories of
+// searchPath.
+func NewLoader(searchPath ...string) *Loader {
+	return &Loader{SearchPath: searchPath}
+}
+
+// EvalFile evaluates the script at path in a new environment. Relative
+// imports of the script are resolved against its directory.
+func (interpreter *Interpreter) EvalFile(path string) object.Object {
+	file, err := filepath.Abs(path)
+	if err != nil {
+		return newError("cannot read %s: %s", path, err)
+	}
+
+	return interpreter.run(context.Background(), func() object.Object {
+		interpreter.script = file
+		defer func() { interpreter.script = "" }()
+
+		value, _, _ := interpreter.loader().evalFile(interpreter, file, false)
+		return value
+	})
+}
+
+func (interpreter *Interpreter) loader() *Loader {
+	if interpreter.Modules == nil {
+		interpreter.Modules = NewLoader()
+	}
+	return interpreter.Modules
+}
+
+func (interpreter *Interpreter) evalImportStatement(statement *ast.ImportStatement, env *object.Environment) object.Object {
+	module, err := interpreter.loader().load(interpreter, statement.Path.Value)
+	if err != nil {
+		return err
+	}
+
+	interpreter.bind(env, statement.Alias.Value, module)
+
+	return NULL
+}
+
+func (loader *Loader) load(interpreter *Interpreter, path string) (*object.Module, *object.Error) {
+	file, err := loader.resolve(path, interpreter.loading)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := interpreter.allowedImport(path, file); err != nil {
+		return nil, err
+	}
+
+	if module, ok := loader.module(file); ok {
+		return module, nil
+	}
+
+	value, env, program := loader.evalFile(interpreter, file, true)
+	if err, ok := value.(*object.Error); ok {
+		return nil, err
+	}
+
+	module := &object.Module{Path: file, Names: []string{}, Exports: map[string]object.Object{}}
+
+	for _, statement := range program.Statements {
+		if export, ok := statement.(*ast.ExportStatement); ok {
+			for _, name := range export.Names() {
+				value, ok := env.Get(name.Value)
+				// The module returned before the export
+				if !ok {
+					return nil, newError("cannot import %s: export %s was not evaluated", file, name.Value)
+				}
+				if _, exists := module.Exports[name.Value]; !exists {
+					module.Names = append(module.Names, name.Value)
+				}
+				module.Exports[name.Value] = value
+			}
+		}
+	}
+
+	return loader.store(file, module), nil
+}
+
+func (loader *Loader) module(file string) (*object.Module, bool) {
+	loader.mutex.Lock()
+	defer loader.mutex.Unlock()
+
+	module, ok := loader.modules[file]
+	return module, ok
+}
+
+// store caches module unless another interpreter stored the module of file
+// first. It returns the cached module.
+func (loader *Loader) store(file string, module *object.Module) *object.Module {
+	loader.mutex.Lock()
+	defer loader.mutex.Unlock()
+
+	if stored, ok := loader.modules[file]; ok {
+		return stored
+	}
+
+	if loader.modules == nil {
+		loader.modules = map[string]*object.Module{}
+	}
+	loader.modules[file] = module
+	return module
+}
+
+// allowedImport reports an error unless the capabilities of interpreter
+// allow importing the module at the absolute path file.
+func (interpreter *Interpreter) allowedImport(path, file string) *object.Error {
+	allowed := append(slices.Clone(interpreter.loader().SearchPath), interpreter.Capabilities.Imports...)
+	if interpreter.script != "" {
+		allowed = append(allowed, filepath.Dir(interpreter.script))
+	}
+
+	if _, err := allowedPath("import", file, allowed); err != nil {
+		return newError("import: access to %s denied", path)
+	}
+	return nil
+}
+
+// evalFile parses and evaluates the script at the absolute path file in a
+// new environment. It fails if the script is already being evaluated, as
+// its imports would then form a cycle. The syntax errors of modules are
+// not reported, as they could reveal the contents of files which are no
+// scripts.
+func (loader *Loader) evalFile(interpreter *Interpreter, file string, module bool) (object.Object, *object.Environment, *ast.Program) {
+	if index := slices.Index(interpreter.loading, file); index >= 0 {
+		cycle := append(slices.Clone(interpreter.loading[index:]), file)
+		return newError("import cycle: %s", strings.Join(cycle, " -> ")), nil, nil
+	}
+
+	source, err := os.ReadFile(file)
+	if err != nil {
+		return newError("cannot read %s: %s", file, err), nil, nil
+	}
+
+	parseErrors, program := parser.New(lexer.New(string(source))).ParseProgram()
+	if parseErrors != nil && module {
+		return newError("cannot parse %s: invalid syntax", file), nil, nil
+	}
+	if parseErrors != nil {
+		return newError("cannot parse %s: %s", file, strings.Join(parseErrors, ", ")), nil, nil
+	}
+
+	env := interpreter.newEnvironment(nil)
+
+	interpreter.loading = append(interpreter.loading, file)
+	defer func() { interpreter.loading = interpreter.loading[:len(interpreter.loading)-1] }()
+
+	return interpreter.eval(program, env), env, program
+}
+
+// resolve returns the absolute path of the script an import path refers to.
+// Relative paths are resolved against the innermost script of loading.
+func (loader *Loader) resolve(path string, loading []string) (string, *object.Error) {
+	candidates := []string{}
+
+	switch {
+	case strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../"):
+		dir := "."
+		if count := len(loading); count > 0 {
+			dir = filepath.Dir(loading[count-1])
+		}
+		candidates = append(candidates, filepath.Join(dir, path))
+
+	case filepath.IsAbs(path):
+		candidates = append(candidates, path)
+
+	default:
+		for _, dir := range loader.SearchPath {
+			candidates = append(candidates, filepath.Join(dir, path))
+		}
+	}
+
+	for _, candidate := range candidates {
+		_, err := os.Stat(candidate)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", newError("cannot read %s: %s", candidate, err)
+		}
+
+		file, err := filepath.Abs(candidate)
+		if err != nil {
+			return "", newError("cannot resolve %s: %s", candidate, err)
+		}
+		return file, nil
+	}
+
+	return "", newError("cannot find module %q", path)
+}
@@ -0,0 +1,194 @@
+package eval
+
+import (
+	"monkey/object"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeFiles creates the given files below dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, source := range files {
+		path := filepath.Join(dir, name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.Nil(t, os.WriteFile(path, []byte(source), 0o644))
+	}
+}
+
+func TestImports(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"math.mk":          "export fn double(x) { x * 2 } export let [one, two] = [1, 2]; let hidden = 3;",
+		"nested/shapes.mk": `import "../math.mk" as math; export let area = fn([w, h]) { math.double(w * h) };`,
+		"lib/strings.mk":   `export let greeting = "hello";`,
+		"cycle/a.mk":       `import "./b.mk" as b;`,
+		"cycle/b.mk":       `import "./a.mk" as a;`,
+		"broken.mk":        "export let = 1;",
+		"failing.mk":       "export let a = 1; undefined;",
+		"nested_export.mk": "if (true) { export let a = 1; }",
+		"returning.mk":     "export let a = 1; return 2; export let b = 3;",
+	})
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			"relative import",
+			`import "./math.mk" as m; [m.double(2), m.one, m.two]`,
+			"[4, 1, 2]",
+		},
+		{
+			"imports of modules are relative to the module",
+			`import "./nested/shapes.mk" as shapes; shapes.area([2, 3])`,
+			"12",
+		},
+		{
+			"search path",
+			`import "strings.mk" as s; s.greeting`,
+			"hello",
+		},
+		{
+			"namespace",
+			`import "./math.mk" as m; m`,
+			"module math.mk {double, one, two}",
+		},
+		{
+			"import is a declaration",
+			`import "./math.mk" as m;`,
+			"null",
+		},
+		{
+			"unexported binding",
+			`import "./math.mk" as m; m.hidden`,
+			"Error: math.mk does not export hidden",
+		},
+		{
+			"optional member of module",
+			`import "./math.mk" as m; m?.one`,
+			"1",
+		},
+		{
+			"missing module",
+			`import "./missing.mk" as m;`,
+			`Error: cannot find module "./missing.mk"`,
+		},
+		{
+			"missing module in search path",
+			`import "missing.mk" as m;`,
+			`Error: cannot find module "missing.mk"`,
+		},
+		{
+			"cycle",
+			`import "./cycle/a.mk" as a;`,
+			"Error: import cycle: " + filepath.Join(dir, "cycle/a.mk") + " -> " + filepath.Join(dir, "cycle/b.mk") + " -> " + filepath.Join(dir, "cycle/a.mk"),
+		},
+		{
+			"parser errors",
+			`import "./broken.mk" as b;`,
+			"Error: cannot parse " + filepath.Join(dir, "broken.mk") + ": invalid syntax",
+		},
+		{
+			"exports must be evaluated",
+			`import "./returning.mk" as r; r.b`,
+			"Error: cannot import " + filepath.Join(dir, "returning.mk") + ": export b was not evaluated",
+		},
+		{
+			"errors abort the import",
+			`import "./failing.mk" as f; 1`,
+			"Error: identifier not found undefined",
+		},
+		{
+			"export only at top level",
+			`import "./nested_export.mk" as n;`,
+			"Error: cannot parse " + filepath.Join(dir, "nested_export.mk") + ": invalid syntax",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			writeFiles(t, dir, map[string]string{"main.mk": testCase.input})
+
+			interpreter := &Interpreter{Modules: NewLoader(filepath.Join(dir, "lib"))}
+			actual := interpreter.EvalFile(filepath.Join(dir, "main.mk"))
+
+			assert.Equal(t, testCase.expected, actual.Inspect())
+		})
+	}
+}
+
+func TestImportOutsideOfScript(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"lib/math.mk":        "export let one = 1;",
+		"outside/secret.txt": "secret_token_value",
+		"script/main.mk":     `import "../outside/secret.txt" as s;`,
+	})
+
+	testCases := []struct {
+		name         string
+		capabilities Capabilities
+		input        string
+		expected     string
+	}{
+		{"no capabilities", Capabilities{}, `import "` + filepath.Join(dir, "lib/math.mk") + `" as m; m.one`, "Error: import: access to " + filepath.Join(dir, "lib/math.mk") + " denied"},
+		{"relative", Capabilities{}, `import "./math.mk" as m;`, "Error: cannot find module \"./math.mk\""},
+		{"granted", Capabilities{Imports: []string{filepath.Join(dir, "lib")}}, `import "` + filepath.Join(dir, "lib/math.mk") + `" as m; m.one`, "1"},
+		{"read is no import", Capabilities{Read: []string{dir}}, `import "` + filepath.Join(dir, "outside/secret.txt") + `" as s;`, "Error: import: access to " + filepath.Join(dir, "outside/secret.txt") + " denied"},
+		{"granted directory", Capabilities{Imports: []string{dir}}, `import "` + filepath.Join(dir, "outside/secret.txt") + `" as s;`, "Error: identifier not found secret_token_value"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual := evalWithCapabilities(testCase.capabilities, testCase.input)
+
+			assert.Equal(t, testCase.expected, actual.Inspect())
+		})
+	}
+
+	interpreter := &Interpreter{}
+	actual := interpreter.EvalFile(filepath.Join(dir, "script/main.mk"))
+
+	assert.Equal(t, "Error: import: access to ../outside/secret.txt denied", actual.Inspect())
+}
+
+func TestModulesAreEvaluatedOnce(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.mk":  `import "./a.mk" as a; import "./b.mk" as b; [a.value, b.value]`,
+		"a.mk":     `import "./util.mk" as util; export let value = util;`,
+		"b.mk":     `import "./util.mk" as util; export let value = util;`,
+		"util.mk":  "export let x = 1;",
+		"other.mk": "1 + 1",
+	})
+
+	interpreter := &Interpreter{}
+	result := interpreter.EvalFile(filepath.Join(dir, "main.mk"))
+
+	modules := result.(*object.Array).Elements
+	assert.Same(t, modules[0], modules[1])
+	assert.Len(t, interpreter.Modules.modules, 3)
+	assert.Empty(t, interpreter.loading)
+
+	// Scripts evaluated by EvalFile are no modules, so they return their value
+	assert.Equal(t, "2", interpreter.EvalFile(filepath.Join(dir, "other.mk")).Inspect())
+}
+
+func TestEvalFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"self.mk": `import "./self.mk" as self;`,
+	})
+
+	interpreter := &Interpreter{}
+
+	self := filepath.Join(dir, "self.mk")
+	assert.Equal(t, "Error: import cycle: "+self+" -> "+self, interpreter.EvalFile(self).Inspect())
+
+	missing := filepath.Join(dir, "missing.mk")
+	assert.Contains(t, interpreter.EvalFile(missing).Inspect(), "Error: cannot read "+missing)
+}
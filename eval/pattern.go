@@ -0,0 +1,129 @@
+package eval
+
+import (
+	"monkey/ast"
+	"monkey/object"
+)
+
+// matchPattern reports whether value matches pattern and binds the names
+// of the pattern in env. If the value does not match, some of the names
+// might be bound already.
+func (interpreter *Interpreter) matchPattern(pattern ast.Pattern, value object.Object, env *object.Environment) bool {
+	return interpreter.destructure(pattern, value, env) == nil
+}
+
+// A mismatch describes why a value does not match a pattern. Failing to
+// match is common in match expressions, so the message is only formatted
+// once it is reported.
+type mismatch struct {
+	format string
+	args   []any
+}
+
+func newMismatch(format string, a ...any) *mismatch {
+	return &mismatch{format: format, args: a}
+}
+
+func (mismatch *mismatch) error() *object.Error {
+	args := []any{}
+	for _, arg := range mismatch.args {
+		if value, ok := arg.(object.Object); ok {
+			arg = value.Inspect()
+		}
+		args = append(args, arg)
+	}
+
+	return newError(mismatch.format, args...)
+}
+
+// destructure binds the names of pattern to the matching parts of value in
+// env. It describes the first part of value which does not have the shape
+// the pattern expects.
+func (interpreter *Interpreter) destructure(pattern ast.Pattern, value object.Object, env *object.Environment) *mismatch {
+	switch pattern := pattern.(type) {
+	case *ast.WildcardPattern:
+		return nil
+
+	case *ast.Identifier:
+		interpreter.bind(env, pattern.Value, value)
+		return nil
+
+	case *ast.LiteralPattern:
+		if !isEqual(interpreter.eval(pattern.Value, env), value) {
+			return newMismatch("%s does not match %s", value, pattern)
+		}
+		return nil
+
+	case *ast.ArrayPattern:
+		array, ok := value.(*object.Array)
+		if !ok {
+			return newMismatch("cannot destructure %s with %s", value.Type(), pattern)
+		}
+
+		count := len(pattern.Elements)
+		if pattern.Rest == nil && len(array.Elements) != count {
+			return newMismatch("expected %d elements for %s, got %d", count, pattern, len(array.Elements))
+		}
+		if len(array.Elements) < count {
+			return newMismatch("expected at least %d elements for %s, got %d", count, pattern, len(array.Elements))
+		}
+
+		for index, element := range pattern.Elements {
+			if mismatch := interpreter.destructure(element, array.Elements[index], env); mismatch != nil {
+				return mismatch
+			}
+		}
+
+		if pattern.Rest != nil {
+			rest := append([]object.Object{}, array.Elements[count:]...)
+			return interpreter.destructure(pattern.Rest, &object.Array{Elements: rest}, env)
+		}
+
+		return nil
+
+	case *ast.HashPattern:
+		hash, ok := value.(*object.Hash)
+		if !ok {
+			return newMismatch("cannot destructure %s with %s", value.Type(), pattern)
+		}
+
+		for _, pair := range pattern.Pairs {
+			key, ok := interpreter.eval(pair.Key, env).(object.Hashable)
+			if !ok {
+				return newMismatch("unusable as hash key: %s", pair.Key)
+			}
+
+			member, ok := hash.Get(key)
+			if !ok {
+				return newMismatch("missing key %s for %s", pair.Key, pattern)
+			}
+
+			if mismatch := interpreter.destructure(pair.Value, member, env); mismatch != nil {
+				return mismatch
+			}
+		}
+
+		return nil
+	}
+
+	return newMismatch("unknown pattern %s", pattern)
+}
+
+// isEqual reports whether two values of literals are equal. Like ==, it
+// compares an integer with a float by its value.
+func isEqual(a object.Object, b object.Object) bool {
+	if a == NULL || b == NULL {
+		return a == b
+	}
+
+	if a.Type() == object.FLOAT_OBJECT || b.Type() == object.FLOAT_OBJECT {
+		floatA, okA := floatValue(a)
+		floatB, okB := floatValue(b)
+		return okA && okB && floatA == floatB
+	}
+
+	hashableA, okA := a.(object.Hashable)
+	hashableB, okB := b.(object.Hashable)
+
+	return okA && okB && hashableA.HashKey() == hashableB.HashKey()
+}
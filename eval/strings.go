@@ -0,0 +1,306 @@
+package eval
+
+import (
+	"monkey/object"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Indices and lengths of strings count characters, not bytes.
+var stringBuiltins = []*object.Builtin{
+	{Name: "split", Fn: builtinSplit},
+	{Name: "join", Fn: builtinJoin},
+	{Name: "trim", Fn: builtinTrim},
+	{Name: "contains", Fn: builtinContains},
+	{Name: "index_of", Fn: builtinIndexOf},
+	{Name: "replace", Fn: builtinReplace},
+	{Name: "upper", Fn: builtinUpper},
+	{Name: "lower", Fn: builtinLower},
+	{Name: "starts_with", Fn: builtinStartsWith},
+	{Name: "ends_with", Fn: builtinEndsWith},
+	{Name: "repeat", Fn: builtinRepeat},
+	{Name: "substring", Fn: builtinSubstring},
+	{Name: "format", Fn: builtinFormat},
+}
+
+// builtinSplit splits a string around every separator. An empty separator
+// splits it into its characters.
+func builtinSplit(runtime object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("split", arguments, 2, object.STRING_OBJECT, object.STRING_OBJECT); err != nil {
+		return err
+	}
+
+	str, separator := stringValue(arguments[0]), stringValue(arguments[1])
+	count := strings.Count(str, separator) + 1
+	if separator == "" {
+		count = utf8.RuneCountInString(str)
+	}
+	if count > maxLength {
+		return newError("split: %d parts exceed the maximum of %d", count, maxLength)
+	}
+	size := arraySize + int64(count)*(elementSize+stringSize) + int64(len(str))
+	if err := runtime.Reserve(count+1, size); err != nil {
+		return err
+	}
+
+	parts := strings.Split(str, separator)
+
+	elements := make([]object.Object, len(parts))
+	for index, part := range parts {
+		elements[index] = &object.String{Value: part}
+	}
+
+	return &object.Array{Elements: elements}
+}
+
+// builtinJoin concatenates an array of strings with a separator between
+// them, which is empty if it is omitted.
+func builtinJoin(runtime object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("join", arguments, 1, object.ARRAY_OBJECT, object.STRING_OBJECT); err != nil {
+		return err
+	}
+
+	separator := ""
+	if len(arguments) > 1 {
+		separator = stringValue(arguments[1])
+	}
+
+	elements := arguments[0].(*object.Array).Elements
+	parts := make([]string, len(elements))
+	length := int64(0)
+	for index, element := range elements {
+		str, ok := element.(*object.String)
+		if !ok {
+			return newError("join: element %d must be STRING, got %s", index, element.Type())
+		}
+		parts[index] = str.Value
+		length += int64(len(str.Value))
+	}
+	if len(parts) > 1 {
+		length += int64(len(separator)) * int64(len(parts)-1)
+	}
+	if err := reserveString(runtime, "join", length); err != nil {
+		return err
+	}
+
+	return &object.String{Value: strings.Join(parts, separator)}
+}
+
+// builtinTrim removes leading and trailing white space, or the characters
+// of the optional second argument.
+func builtinTrim(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("trim", arguments, 1, object.STRING_OBJECT, object.STRING_OBJECT); err != nil {
+		return err
+	}
+
+	if len(arguments) > 1 {
+		return &object.String{Value: strings.Trim(stringValue(arguments[0]), stringValue(arguments[1]))}
+	}
+	return &object.String{Value: strings.TrimSpace(stringValue(arguments[0]))}
+}
+
+func builtinContains(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("contains", arguments, 2, object.STRING_OBJECT, object.STRING_OBJECT); err != nil {
+		return err
+	}
+	return nativeBoolToBooleanObject(strings.Contains(stringValue(arguments[0]), stringValue(arguments[1])))
+}
+
+// builtinIndexOf returns the index of the first occurrence of a substring,
+// or -1 if there is none.
+func builtinIndexOf(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("index_of", arguments, 2, object.STRING_OBJECT, object.STRING_OBJECT); err != nil {
+		return err
+	}
+
+	str := stringValue(arguments[0])
+
+	index := strings.Index(str, stringValue(arguments[1]))
+	if index > 0 {
+		index = utf8.RuneCountInString(str[:index])
+	}
+
+	return &object.Integer{Value: int64(index)}
+}
+
+// builtinReplace replaces all occurrences of a substring.
+func builtinReplace(runtime object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("replace", arguments, 3, object.STRING_OBJECT, object.STRING_OBJECT, object.STRING_OBJECT); err != nil {
+		return err
+	}
+
+	str, old, replacement := stringValue(arguments[0]), stringValue(arguments[1]), stringValue(arguments[2])
+	count := int64(strings.Count(str, old))
+	length := int64(len(str)) + count*int64(len(replacement)-len(old))
+	if err := reserveString(runtime, "replace", length); err != nil {
+		return err
+	}
+
+	replaced := strings.ReplaceAll(str, old, replacement)
+	return &object.String{Value: replaced}
+}
+
+func builtinUpper(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("upper", arguments, 1, object.STRING_OBJECT); err != nil {
+		return err
+	}
+	return &object.String{Value: strings.ToUpper(stringValue(arguments[0]))}
+}
+
+func builtinLower(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("lower", arguments, 1, object.STRING_OBJECT); err != nil {
+		return err
+	}
+	return &object.String{Value: strings.ToLower(stringValue(arguments[0]))}
+}
+
+func builtinStartsWith(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("starts_with", arguments, 2, object.STRING_OBJECT, object.STRING_OBJECT); err != nil {
+		return err
+	}
+	return nativeBoolToBooleanObject(strings.HasPrefix(stringValue(arguments[0]), stringValue(arguments[1])))
+}
+
+func builtinEndsWith(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("ends_with", arguments, 2, object.STRING_OBJECT, object.STRING_OBJECT); err != nil {
+		return err
+	}
+	return nativeBoolToBooleanObject(strings.HasSuffix(stringValue(arguments[0]), stringValue(arguments[1])))
+}
+
+func builtinRepeat(runtime object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("repeat", arguments, 2, object.STRING_OBJECT, object.INTEGER_OBJECT); err != nil {
+		return err
+	}
+
+	str := stringValue(arguments[0])
+	count := arguments[1].(*object.Integer).Value
+	if count < 0 {
+		return newError("repeat: negative count %d", count)
+	}
+	// Divides instead of multiplying, which could overflow
+	if len(str) > 0 && count > maxLength/int64(len(str)) {
+		return newError("repeat: result exceeds the maximum length of %d bytes", maxLength)
+	}
+	if err := runtime.Reserve(1, stringSize+int64(len(str))*count); err != nil {
+		return err
+	}
+
+	return &object.String{Value: strings.Repeat(str, int(count))}
+}
+
+// builtinSubstring returns the characters from start up to, but not
+// including, end, which defaults to the length of the string. Indices
+// outside of the string are clamped to it.
+func builtinSubstring(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("substring", arguments, 2, object.STRING_OBJECT, object.INTEGER_OBJECT, object.INTEGER_OBJECT); err != nil {
+		return err
+	}
+
+	runes := []rune(stringValue(arguments[0]))
+	length := int64(len(runes))
+
+	start := arguments[1].(*object.Integer).Value
+	end := length
+	if len(arguments) > 2 {
+		end = arguments[2].(*object.Integer).Value
+	}
+
+	start = min(max(start, 0), length)
+	end = min(max(end, start), length)
+
+	return &object.String{Value: string(runes[start:end])}
+}
+
+// builtinFormat replaces the verbs of a format string by its other
+// arguments in order:
+//
+//	%s  any value as it is inspected
+//	%d  an integer
+//	%q  a string in double quotes with Go escapes
+//	%%  a literal percent sign
+func builtinFormat(runtime object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArgumentCount("format", arguments, 1, -1); err != nil {
+		return err
+	}
+	if arguments[0].Type() != object.STRING_OBJECT {
+		return argumentTypeError("format", 0, arguments[0], object.STRING_OBJECT)
+	}
+
+	var out strings.Builder
+
+	values := arguments[1:]
+	next := 0
+
+	format := stringValue(arguments[0])
+	for index := 0; index < len(format); index++ {
+		if format[index] != '%' {
+			out.WriteByte(format[index])
+			continue
+		}
+
+		index++
+		if index == len(format) {
+			return newError("format: missing verb at end of format")
+		}
+
+		verb := format[index]
+		if verb == '%' {
+			out.WriteByte('%')
+			continue
+		}
+
+		if next == len(values) {
+			return newError("format: missing argument for %%%c", verb)
+		}
+		value := values[next]
+		next++
+
+		var formatted string
+
+		switch verb {
+		case 's':
+			formatted = value.Inspect()
+		case 'd':
+			integer, ok := value.(*object.Integer)
+			if !ok {
+				return newError("format: %%d expects INTEGER, got %s", value.Type())
+			}
+			formatted = strconv.FormatInt(integer.Value, 10)
+		case 'q':
+			str, ok := value.(*object.String)
+			if !ok {
+				return newError("format: %%q expects STRING, got %s", value.Type())
+			}
+			formatted = strconv.Quote(str.Value)
+		default:
+			return newError("format: unknown verb %%%c", verb)
+		}
+
+		// The rest of the format is shorter than all of it
+		if err := reserveString(runtime, "format", int64(out.Len()+len(formatted)+len(format))); err != nil {
+			return err
+		}
+		out.WriteString(formatted)
+	}
+
+	if unused := len(values) - next; unused > 0 {
+		return newError("format: %d arguments unused", unused)
+	}
+
+	return &object.String{Value: out.String()}
+}
+
+// reserveString aborts the evaluation if a string of length bytes would
+// exceed its limits, before a builtin creates it.
+func reserveString(runtime object.Runtime, name string, length int64) *object.Error {
+	if length > maxLength {
+		return newError("%s: result exceeds the maximum length of %d bytes", name, maxLength)
+	}
+	return runtime.Reserve(1, stringSize+length)
+}
+
+func stringValue(value object.Object) string {
+	return value.(*object.String).Value
+}
@@ -0,0 +1,245 @@
+package eval
+
+import (
+	"math/rand"
+	"monkey/object"
+	"reflect"
+	"slices"
+)
+
+// The task builtins run functions concurrently. Tasks share the limits and
+// the context of the evaluation which spawned them and are canceled when it
+// ends. The builtins which wait return once the evaluation is canceled.
+var taskBuiltins = []*object.Builtin{
+	{Name: "spawn", Fn: builtinSpawn},
+	{Name: "await", Fn: builtinAwait},
+	{Name: "channel", Fn: builtinChannel},
+	{Name: "send", Fn: builtinSend},
+	{Name: "recv", Fn: builtinRecv},
+	{Name: "close", Fn: builtinClose},
+	{Name: "select", Fn: builtinSelect},
+}
+
+// Spawn calls function with arguments on a goroutine of its own and returns
+// its task. The call shares the limits and the context of the running
+// evaluation, but has random numbers of its own.
+func (interpreter *Interpreter) Spawn(function object.Object, arguments ...object.Object) *object.Task {
+	task := object.NewTask()
+	child := interpreter.fork()
+
+	go func() {
+		defer close(task.Done)
+		task.Result = child.Call(function, arguments...)
+	}()
+
+	return task
+}
+
+// fork returns an interpreter with the configuration and the running
+// evaluation of interpreter for a task.
+func (interpreter *Interpreter) fork() *Interpreter {
+	// The builtins of the capabilities must not be created by two tasks
+	interpreter.hostBuiltin("")
+
+	child := &Interpreter{
+		Truthy:       interpreter.Truthy,
+		Modules:      interpreter.loader(),
+		Random:       rand.New(rand.NewSource(interpreter.Rand().Int63())),
+		Capabilities: interpreter.Capabilities,
+		Limits:       interpreter.Limits,
+		ErrorValues:  interpreter.ErrorValues,
+		granted:      interpreter.granted,
+		running:      interpreter.running,
+		loading:      slices.Clone(interpreter.loading),
+		script:       interpreter.script,
+	}
+	child.evaluation.Store(interpreter.current())
+
+	return child
+}
+
+// builtinSpawn calls a function with the rest of its arguments in a new
+// task.
+func builtinSpawn(runtime object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArgumentCount("spawn", arguments, 1, -1); err != nil {
+		return err
+	}
+	if !isCallable(arguments[0]) {
+		return argumentTypeError("spawn", 0, arguments[0], object.FUNCTION_OBJECT, object.BUILTIN_OBJECT)
+	}
+
+	return runtime.Spawn(arguments[0], arguments[1:]...)
+}
+
+// builtinAwait waits for a task and returns its result. The error of a
+// failed task is returned as the error of await.
+func builtinAwait(runtime object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("await", arguments, 1, object.TASK_OBJECT); err != nil {
+		return err
+	}
+
+	task := arguments[0].(*object.Task)
+	select {
+	case <-task.Done:
+		return task.Result
+	case <-runtime.Context().Done():
+		return newError("await: %s", runtime.Context().Err())
+	}
+}
+
+// builtinChannel creates a channel which buffers up to capacity values,
+// which defaults to 0.
+func builtinChannel(runtime object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("channel", arguments, 0, object.INTEGER_OBJECT); err != nil {
+		return err
+	}
+
+	capacity := int64(0)
+	if len(arguments) > 0 {
+		capacity = arguments[0].(*object.Integer).Value
+	}
+	if capacity < 0 {
+		return newError("channel: negative capacity %d", capacity)
+	}
+	if capacity > maxLength {
+		return newError("channel: capacity %d exceeds the maximum of %d", capacity, maxLength)
+	}
+	if err := runtime.Reserve(1, channelSize+elementSize*capacity); err != nil {
+		return err
+	}
+
+	return object.NewChannel(int(capacity))
+}
+
+// builtinSend waits until a value can be sent on a channel.
+func builtinSend(runtime object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArgumentCount("send", arguments, 2, 2); err != nil {
+		return err
+	}
+	channel, ok := arguments[0].(*object.Channel)
+	if !ok {
+		return argumentTypeError("send", 0, arguments[0], object.CHANNEL_OBJECT)
+	}
+
+	// Checked first, as select picks a ready case at random
+	select {
+	case <-channel.Closed:
+		return newError("send: channel is closed")
+	default:
+	}
+
+	select {
+	case channel.Values <- arguments[1]:
+		return NULL
+	case <-channel.Closed:
+		return newError("send: channel is closed")
+	case <-runtime.Context().Done():
+		return newError("send: %s", runtime.Context().Err())
+	}
+}
+
+// builtinRecv waits for a value of a channel. Once the channel is closed
+// and its buffered values are received, it returns null.
+func builtinRecv(runtime object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("recv", arguments, 1, object.CHANNEL_OBJECT); err != nil {
+		return err
+	}
+
+	channel := arguments[0].(*object.Channel)
+	select {
+	case value := <-channel.Values:
+		return value
+	case <-channel.Closed:
+		return receiveClosed(channel)
+	case <-runtime.Context().Done():
+		return newError("recv: %s", runtime.Context().Err())
+	}
+}
+
+// receiveClosed returns the next buffered value of a closed channel, or
+// null if there is none.
+func receiveClosed(channel *object.Channel) object.Object {
+	select {
+	case value := <-channel.Values:
+		return value
+	default:
+		return NULL
+	}
+}
+
+func builtinClose(_ object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("close", arguments, 1, object.CHANNEL_OBJECT); err != nil {
+		return err
+	}
+
+	if !arguments[0].(*object.Channel).Close() {
+		return newError("close: channel is closed already")
+	}
+	return NULL
+}
+
+// builtinSelect waits until one of an array of channels and tasks is
+// ready. It returns its index and the value received from the channel, or
+// the result of the task, as pair. If several are ready, one of them is
+// picked at random.
+func builtinSelect(runtime object.Runtime, arguments ...object.Object) object.Object {
+	if err := checkArguments("select", arguments, 1, object.ARRAY_OBJECT); err != nil {
+		return err
+	}
+
+	elements := arrayElements(arguments[0])
+	if len(elements) == 0 {
+		return newError("select: no channels or tasks")
+	}
+
+	// Channels have a case for values and one for closing
+	type selected struct {
+		index  int
+		closed bool
+	}
+	cases := []reflect.SelectCase{}
+	selects := []selected{}
+
+	for index, element := range elements {
+		switch element := element.(type) {
+		case *object.Channel:
+			cases = append(cases, receiveCase(element.Values), receiveCase(element.Closed))
+			selects = append(selects, selected{index, false}, selected{index, true})
+		case *object.Task:
+			cases = append(cases, receiveCase(element.Done))
+			selects = append(selects, selected{index, false})
+		default:
+			return newError("select: element %d must be CHANNEL or TASK, got %s", index, element.Type())
+		}
+	}
+
+	cases = append(cases, receiveCase(runtime.Context().Done()))
+
+	chosen, received, _ := reflect.Select(cases)
+	if chosen == len(cases)-1 {
+		return newError("select: %s", runtime.Context().Err())
+	}
+
+	var value object.Object
+	switch element := elements[selects[chosen].index].(type) {
+	case *object.Task:
+		// Like await, select fails with the task
+		if isError(element.Result) {
+			return element.Result
+		}
+		value = element.Result
+	case *object.Channel:
+		if selects[chosen].closed {
+			value = receiveClosed(element)
+		} else {
+			value = received.Interface().(object.Object)
+		}
+	}
+
+	index := &object.Integer{Value: int64(selects[chosen].index)}
+	return &object.Array{Elements: []object.Object{index, value}}
+}
+
+func receiveCase(channel any) reflect.SelectCase {
+	return reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(channel)}
+}
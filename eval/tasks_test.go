@@ -0,0 +1,107 @@
+package eval
+
+import (
+	"context"
+	"monkey/object"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskBuiltins(t *testing.T) {
+	testBuiltins(t, []struct{ input, expected string }{
+		{"await(spawn(fn(x) { x * 2 }, 21))", "42"},
+		{"await(spawn(len, [1, 2]))", "2"},
+		{"let tasks = map(range(5), fn(i) { spawn(fn() { i * i }) }); map(tasks, await)", "[0, 1, 4, 9, 16]"},
+		{"let task = spawn(fn() { 1 }); await(task); [task, await(task)]", "[task done, 1]"},
+		{"await(spawn(fn() { 1 + true }))", "Error: type mismatch INTEGER + BOOLEAN"},
+		{"spawn(1)", "Error: spawn: argument 1 must be FUNCTION or BUILTIN, got INTEGER"},
+		{"spawn()", "Error: spawn: expected at least 1 arguments got only 0"},
+		{"await(1)", "Error: await: argument 1 must be TASK, got INTEGER"},
+	})
+}
+
+func TestChannelBuiltins(t *testing.T) {
+	testBuiltins(t, []struct{ input, expected string }{
+		{"channel()", "channel(0)"},
+		{"channel(2)", "channel(2)"},
+		{
+			"let c = channel(); spawn(fn() { each(range(3), fn(i) { send(c, i) }); close(c) }); [recv(c), recv(c), recv(c), recv(c)]",
+			"[0, 1, 2, null]",
+		},
+		{"let c = channel(2); send(c, 1); send(c, 2); close(c); [recv(c), recv(c), recv(c)]", "[1, 2, null]"},
+		{
+			"let results = channel(); each(range(3), fn(i) { spawn(fn() { send(results, i * 10) }) }); sort([recv(results), recv(results), recv(results)])",
+			"[0, 10, 20]",
+		},
+		{"let c = channel(1); close(c); send(c, 1)", "Error: send: channel is closed"},
+		{"let c = channel(); close(c); close(c)", "Error: close: channel is closed already"},
+		{"channel(-1)", "Error: channel: negative capacity -1"},
+		{"channel(4611686018427387904)", "Error: channel: capacity 4611686018427387904 exceeds the maximum of 268435456"},
+		{"send(1, 2)", "Error: send: argument 1 must be CHANNEL, got INTEGER"},
+		{"recv(1)", "Error: recv: argument 1 must be CHANNEL, got INTEGER"},
+	})
+}
+
+func TestSelect(t *testing.T) {
+	testBuiltins(t, []struct{ input, expected string }{
+		{`let a = channel(); let b = channel(1); send(b, "b"); select([a, b])`, "[1, b]"},
+		{"select([channel(), spawn(fn() { 1 })])", "[1, 1]"},
+		{"let c = channel(); close(c); select([c])", "[0, null]"},
+		{"let c = channel(1); send(c, 1); close(c); [select([c]), select([c])]", "[[0, 1], [0, null]]"},
+		{"select([spawn(fn() { 1 + true })])", "Error: type mismatch INTEGER + BOOLEAN"},
+		{"select([])", "Error: select: no channels or tasks"},
+		{"select([1])", "Error: select: element 0 must be CHANNEL or TASK, got INTEGER"},
+	})
+}
+
+func TestTasksShareEnvironments(t *testing.T) {
+	// Run with -race: tasks read the environment the evaluation writes to
+	input := `
+		let base = 1;
+		let tasks = map(range(8), fn(i) { spawn(fn() { reduce(range(100), fn(sum, j) { sum + base }, 0) }) });
+		let a = 1; let b = 2; let c = 3; let d = 4;
+		map(tasks, await)
+	`
+
+	actual := evalWithLimits(context.Background(), Limits{}, input)
+
+	assert.Equal(t, "[100, 100, 100, 100, 100, 100, 100, 100]", actual.Inspect())
+}
+
+func TestTaskLimits(t *testing.T) {
+	testCases := []struct {
+		name     string
+		limits   Limits
+		input    string
+		expected string
+	}{
+		{"steps of tasks", Limits{Steps: 1000}, "let loop = fn() { loop() }; await(spawn(loop))", "Error: step limit of 1000 exceeded"},
+		{"abort wakes up waiting", Limits{Steps: 1000}, "let loop = fn() { loop() }; spawn(loop); recv(channel())", "Error: step limit of 1000 exceeded"},
+		{"depth of tasks", Limits{Depth: 10}, "let loop = fn() { loop() }; await(spawn(loop))", "Error: call depth limit of 10 exceeded"},
+		{"timeout of recv", Limits{Timeout: time.Millisecond}, "recv(channel())", "Error: timeout of 1ms exceeded"},
+		{"timeout of send", Limits{Timeout: time.Millisecond}, "send(channel(), 1)", "Error: timeout of 1ms exceeded"},
+		{"timeout of select", Limits{Timeout: time.Millisecond}, "select([channel()])", "Error: timeout of 1ms exceeded"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual := evalWithLimits(context.Background(), testCase.limits, testCase.input)
+
+			assert.Equal(t, testCase.expected, actual.Inspect())
+		})
+	}
+}
+
+func TestAwaitCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	actual := evalWithLimits(ctx, Limits{}, "await(spawn(fn() { recv(channel()) }))").(*object.Error)
+
+	assert.Equal(t, object.CANCELED_ERROR, actual.Kind)
+}
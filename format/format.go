@@ -0,0 +1,609 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/parser"
+	"monkey/token"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const indentation = "\t"
+
+// Source formats Monkey source code in the canonical style. Comments are
+// kept and a single blank line is preserved wherever the source has one or
+// more blank lines between statements. Source fails if the input can not be
+// parsed.
+func Source(source string) (string, error) {
+	errors, program := parser.New(lexer.New(source)).ParseProgram()
+
+	if errors != nil {
+		return "", fmt.Errorf("%s", strings.Join(errors, "\n"))
+	}
+
+	printer := &printer{
+		lines:    strings.Split(source, "\n"),
+		comments: program.Comments,
+	}
+	printer.program(program)
+
+	return printer.out.String(), nil
+}
+
+// Program formats a parsed program in the canonical style. As the source is
+// unknown, no blank lines are preserved.
+func Program(program *ast.Program) string {
+	printer := &printer{comments: program.Comments}
+	printer.program(program)
+
+	return printer.out.String()
+}
+
+type printer struct {
+	out bytes.Buffer
+
+	lines    []string       // source lines to look up blank lines, may be nil
+	comments []*ast.Comment // comments which are not yet printed
+
+	depth        int
+	atBlockStart bool // nothing printed yet in the current block
+	lastLine     int  // source line the last printed element ended on
+}
+
+func (printer *printer) write(text string) {
+	printer.out.WriteString(text)
+}
+
+func (printer *printer) writeIndent() {
+	printer.write(strings.Repeat(indentation, printer.depth))
+}
+
+func (printer *printer) program(program *ast.Program) {
+	printer.atBlockStart = true
+
+	printer.statements(program.Statements, token.Position{})
+
+	for len(printer.comments) > 0 {
+		printer.comment(printer.comments[0])
+	}
+}
+
+// statements prints a list of statements which ends before end. A zero end
+// position marks the end of the program.
+func (printer *printer) statements(statements []ast.Statement, end token.Position) {
+	for index, statement := range statements {
+		next := end
+		if index+1 < len(statements) {
+			next = statementPosition(statements[index+1])
+		}
+
+		printer.statement(statement, next)
+	}
+}
+
+func (printer *printer) statement(statement ast.Statement, next token.Position) {
+	position := statementPosition(statement)
+
+	printer.commentsBefore(position)
+	printer.blankLineBefore(position.Line)
+
+	printer.writeIndent()
+	printer.statementBody(statement)
+
+	printer.lastLine = lastLine(statement)
+	printer.trailingComment(next)
+
+	printer.write("\n")
+	printer.atBlockStart = false
+}
+
+// statementBody prints a statement without indentation and comments.
+func (printer *printer) statementBody(statement ast.Statement) {
+	switch statement := statement.(type) {
+	case *ast.LetStatement:
+		printer.write("let ")
+		printer.pattern(statement.Name)
+		printer.write(" = ")
+		printer.expression(statement.Value)
+		printer.write(";")
+
+	case *ast.FunctionStatement:
+		printer.write("fn " + statement.Name.Value)
+		printer.function(statement.Function)
+
+	case *ast.ReturnStatement:
+		printer.write("return ")
+		printer.expression(statement.Value)
+		printer.write(";")
+
+	case *ast.ThrowStatement:
+		printer.write("throw ")
+		printer.expression(statement.Value)
+		printer.write(";")
+
+	case *ast.ExpressionStatement:
+		printer.expression(statement.Value)
+
+		switch statement.Value.(type) {
+		case *ast.IfExpression, *ast.TryExpression:
+		default:
+			printer.write(";")
+		}
+
+	case *ast.BlockStatement:
+		printer.block(statement)
+
+	case *ast.ImportStatement:
+		printer.write("import " + ast.Quote(statement.Path.Value) + " as " + statement.Alias.Value + ";")
+
+	case *ast.ExportStatement:
+		printer.write("export ")
+		printer.statementBody(statement.Statement)
+	}
+}
+
+// trailingComment prints a comment that follows on the last printed line,
+// provided it comes before next.
+func (printer *printer) trailingComment(next token.Position) {
+	if len(printer.comments) > 0 {
+		comment := printer.comments[0]
+		commentPosition := comment.Token.Position
+
+		if commentPosition.Line == printer.lastLine && before(commentPosition, next) {
+			printer.write(" " + commentText(comment))
+			printer.comments = printer.comments[1:]
+		}
+	}
+}
+
+// lastLine returns the source line of the last token of node that is
+// recorded in the tree.
+func lastLine(node ast.Node) int {
+	line := 0
+
+	ast.Inspect(node, func(node ast.Node) bool {
+		if node != nil {
+			for _, field := range []string{"Token", "EndToken"} {
+				if tok := reflect.ValueOf(node).Elem().FieldByName(field); tok.IsValid() {
+					line = max(line, tok.Interface().(token.Token).Position.Line)
+				}
+			}
+		}
+
+		return true
+	})
+
+	return line
+}
+
+func statementPosition(statement ast.Statement) token.Position {
+	switch statement := statement.(type) {
+	case *ast.LetStatement:
+		return statement.Token.Position
+	case *ast.FunctionStatement:
+		return statement.Token.Position
+	case *ast.ImportStatement:
+		return statement.Token.Position
+	case *ast.ExportStatement:
+		return statement.Token.Position
+	case *ast.ReturnStatement:
+		return statement.Token.Position
+	case *ast.ThrowStatement:
+		return statement.Token.Position
+	case *ast.ExpressionStatement:
+		return statement.Token.Position
+	case *ast.BlockStatement:
+		return statement.Token.Position
+	}
+	return token.Position{}
+}
+
+func (printer *printer) block(block *ast.BlockStatement) {
+	end := block.EndToken.Position
+
+	if len(block.Statements) == 0 && !printer.hasCommentBefore(end) {
+		printer.write("{}")
+		return
+	}
+
+	printer.write("{\n")
+	printer.depth += 1
+	printer.atBlockStart = true
+	printer.lastLine = block.Token.Position.Line
+
+	printer.statements(block.Statements, end)
+	printer.commentsBefore(end)
+
+	printer.depth -= 1
+	printer.writeIndent()
+	printer.write("}")
+}
+
+func (printer *printer) hasCommentBefore(position token.Position) bool {
+	return len(printer.comments) > 0 && before(printer.comments[0].Token.Position, position)
+}
+
+func (printer *printer) commentsBefore(position token.Position) {
+	for printer.hasCommentBefore(position) {
+		printer.comment(printer.comments[0])
+	}
+}
+
+func (printer *printer) comment(comment *ast.Comment) {
+	printer.blankLineBefore(comment.Token.Position.Line)
+
+	printer.writeIndent()
+	printer.write(commentText(comment) + "\n")
+
+	printer.comments = printer.comments[1:]
+	printer.atBlockStart = false
+	printer.lastLine = comment.Token.Position.Line
+}
+
+func commentText(comment *ast.Comment) string {
+	return strings.TrimRight(comment.Token.Literal, " \t\r")
+}
+
+// blankLineBefore prints a blank line if the source has at least one blank
+// line between the last printed element and the given line. Blank lines at
+// the start of a block are dropped.
+func (printer *printer) blankLineBefore(line int) {
+	if printer.atBlockStart {
+		return
+	}
+
+	for index := printer.lastLine; index < line-1 && index < len(printer.lines); index++ {
+		if strings.TrimSpace(printer.lines[index]) == "" {
+			printer.write("\n")
+			return
+		}
+	}
+}
+
+func (printer *printer) expression(expression ast.Expression) {
+	switch expression := expression.(type) {
+	case *ast.Identifier:
+		printer.write(expression.Value)
+
+	case *ast.IntegerLiteral:
+		printer.write(strconv.FormatInt(expression.Value, 10))
+
+	case *ast.FloatLiteral:
+		printer.write(ast.FormatFloat(expression.Value))
+
+	case *ast.Boolean:
+		printer.write(strconv.FormatBool(expression.Value))
+
+	case *ast.NullLiteral:
+		printer.write("null")
+
+	case *ast.StringLiteral:
+		printer.write(ast.Quote(expression.Value))
+
+	case *ast.PrefixExpression:
+		// Avoid to print -(-x) as --x
+		right, isPrefix := expression.Right.(*ast.PrefixExpression)
+		isRepeated := isPrefix && right.Operator == expression.Operator && expression.Operator != "!"
+
+		printer.write(expression.Operator)
+		printer.operand(expression.Right, int(parser.PREFIX), isRepeated)
+
+	case *ast.InfixExpression:
+		operatorPrecedence := parser.InfixPrecedence(expression.Token.Type)
+
+		printer.operand(expression.Left, operatorPrecedence, false)
+		printer.write(" " + expression.Operator + " ")
+		printer.operand(expression.Right, operatorPrecedence, true)
+
+	case *ast.IfExpression:
+		printer.write("if (")
+		printer.expression(expression.Condition)
+		printer.write(") ")
+		printer.block(expression.Consequence)
+
+		if expression.Alternative != nil {
+			printer.write(" else ")
+			printer.block(expression.Alternative)
+		}
+
+	case *ast.TryExpression:
+		printer.write("try ")
+		printer.block(expression.Block)
+
+		if expression.Catch != nil {
+			printer.write(" catch (" + expression.Parameter.Value + ") ")
+			printer.block(expression.Catch)
+		}
+
+		if expression.Finally != nil {
+			printer.write(" finally ")
+			printer.block(expression.Finally)
+		}
+
+	case *ast.ConditionalExpression:
+		printer.operand(expression.Condition, int(parser.TERNARY), true)
+		printer.write(" ? ")
+		printer.expression(expression.Consequence)
+		printer.write(" : ")
+		printer.operand(expression.Alternative, int(parser.TERNARY), false)
+
+	case *ast.MatchExpression:
+		printer.match(expression)
+
+	case *ast.FunctionLiteral:
+		printer.write("fn")
+		printer.function(expression)
+
+	case *ast.CallExpression:
+		printer.operand(expression.Function, int(parser.CALL), false)
+		printer.write("(")
+		printer.expressions(expression.Arguments)
+		printer.write(")")
+
+	case *ast.ArrayLiteral:
+		printer.write("[")
+		printer.expressions(expression.Elements)
+		printer.write("]")
+
+	case *ast.HashLiteral:
+		printer.write("{")
+		for index, pair := range expression.Pairs {
+			if index > 0 {
+				printer.write(", ")
+			}
+			printer.expression(pair.Key)
+			printer.write(": ")
+			printer.expression(pair.Value)
+		}
+		printer.write("}")
+
+	case *ast.IndexExpression:
+		printer.operand(expression.Left, int(parser.CALL), false)
+		if expression.Optional {
+			printer.write("?")
+		}
+		printer.write("[")
+		printer.expression(expression.Index)
+		printer.write("]")
+
+	case *ast.MemberExpression:
+		printer.operand(expression.Object, int(parser.CALL), false)
+		printer.write(expression.Token.Literal + expression.Property.Value)
+	}
+}
+
+// match prints a match expression with one arm per line. Every arm ends
+// with a comma.
+func (printer *printer) match(match *ast.MatchExpression) {
+	end := match.EndToken.Position
+
+	printer.write("match (")
+	printer.expression(match.Subject)
+	printer.write(") ")
+
+	if len(match.Arms) == 0 && !printer.hasCommentBefore(end) {
+		printer.write("{}")
+		return
+	}
+
+	printer.write("{\n")
+	printer.depth += 1
+	printer.atBlockStart = true
+	printer.lastLine = match.Token.Position.Line
+
+	for index, arm := range match.Arms {
+		position := patternPosition(arm.Pattern)
+
+		next := end
+		if index+1 < len(match.Arms) {
+			next = patternPosition(match.Arms[index+1].Pattern)
+		}
+
+		printer.commentsBefore(position)
+		printer.blankLineBefore(position.Line)
+
+		printer.writeIndent()
+		printer.pattern(arm.Pattern)
+		printer.write(" => ")
+
+		switch body := arm.Body.(type) {
+		case *ast.BlockStatement:
+			printer.block(body)
+		case ast.Expression:
+			if startsWithHash(body) {
+				// Without parentheses, the hash would be parsed as block
+				printer.write("(")
+				printer.expression(body)
+				printer.write(")")
+				break
+			}
+			printer.expression(body)
+		}
+
+		printer.write(",")
+		printer.lastLine = lastLine(arm)
+		printer.trailingComment(next)
+
+		printer.write("\n")
+		printer.atBlockStart = false
+	}
+
+	printer.commentsBefore(end)
+
+	printer.depth -= 1
+	printer.writeIndent()
+	printer.write("}")
+}
+
+func patternPosition(pattern ast.Pattern) token.Position {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		return pattern.Token.Position
+	case *ast.WildcardPattern:
+		return pattern.Token.Position
+	case *ast.LiteralPattern:
+		return pattern.Token.Position
+	case *ast.ArrayPattern:
+		return pattern.Token.Position
+	case *ast.HashPattern:
+		return pattern.Token.Position
+	}
+	return token.Position{}
+}
+
+func (printer *printer) pattern(pattern ast.Pattern) {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		printer.write(pattern.Value)
+
+	case *ast.WildcardPattern:
+		printer.write("_")
+
+	case *ast.LiteralPattern:
+		printer.expression(pattern.Value)
+
+	case *ast.ArrayPattern:
+		printer.write("[")
+		for index, element := range pattern.Elements {
+			if index > 0 {
+				printer.write(", ")
+			}
+			printer.pattern(element)
+		}
+		if pattern.Rest != nil {
+			if len(pattern.Elements) > 0 {
+				printer.write(", ")
+			}
+			printer.write("...")
+			printer.pattern(pattern.Rest)
+		}
+		printer.write("]")
+
+	case *ast.HashPattern:
+		printer.write("{")
+		for index, pair := range pattern.Pairs {
+			if index > 0 {
+				printer.write(", ")
+			}
+			if pair.Shorthand {
+				printer.pattern(pair.Value)
+				continue
+			}
+			printer.expression(pair.Key)
+			printer.write(": ")
+			printer.pattern(pair.Value)
+		}
+		printer.write("}")
+	}
+}
+
+func (printer *printer) expressions(expressions []ast.Expression) {
+	for index, expression := range expressions {
+		if index > 0 {
+			printer.write(", ")
+		}
+		printer.expression(expression)
+	}
+}
+
+// function prints the parameters and body of a function.
+func (printer *printer) function(function *ast.FunctionLiteral) {
+	printer.write("(")
+
+	for index, parameter := range function.Parameters {
+		if index > 0 {
+			printer.write(", ")
+		}
+		printer.parameter(parameter)
+	}
+
+	printer.write(") ")
+	printer.block(function.Body)
+}
+
+func (printer *printer) parameter(parameter *ast.Parameter) {
+	if parameter.Rest {
+		printer.write("...")
+	}
+
+	printer.pattern(parameter.Name)
+
+	if parameter.Default != nil {
+		printer.write(" = ")
+		printer.expression(parameter.Default)
+	}
+}
+
+// startsWithHash reports whether expression is printed starting with a hash
+// literal, which the parser takes for a block at the start of a match arm
+// body.
+func startsWithHash(expression ast.Expression) bool {
+	switch expression := expression.(type) {
+	case *ast.HashLiteral:
+		return true
+	case *ast.CallExpression:
+		return startsWithHash(expression.Function)
+	case *ast.IndexExpression:
+		return startsWithHash(expression.Left)
+	case *ast.MemberExpression:
+		return startsWithHash(expression.Object)
+	case *ast.InfixExpression:
+		return startsWithHash(expression.Left)
+	case *ast.ConditionalExpression:
+		return startsWithHash(expression.Condition)
+	default:
+		return false
+	}
+}
+
+// operand prints an operand of an operator with the given precedence and
+// wraps it in parentheses if it would bind differently otherwise. Operators
+// are left associative, so right operands of equal precedence need
+// parentheses as well. Calls and index expressions chain in any order, so
+// their operands are checked against the precedence of calls.
+func (printer *printer) operand(expression ast.Expression, operatorPrecedence int, isRight bool) {
+	operandPrecedence := precedence(expression)
+
+	if operandPrecedence < operatorPrecedence || isRight && operandPrecedence == operatorPrecedence {
+		printer.write("(")
+		printer.expression(expression)
+		printer.write(")")
+		return
+	}
+
+	printer.expression(expression)
+}
+
+func precedence(expression ast.Expression) int {
+	switch expression := expression.(type) {
+	case *ast.InfixExpression:
+		return parser.InfixPrecedence(expression.Token.Type)
+	case *ast.PrefixExpression:
+		return int(parser.PREFIX)
+	case *ast.ConditionalExpression:
+		return int(parser.TERNARY)
+	case *ast.CallExpression:
+		return int(parser.CALL)
+	case *ast.IndexExpression, *ast.MemberExpression:
+		return int(parser.INDEX)
+	default:
+		return int(parser.INDEX) + 1
+	}
+}
+
+func isEnd(position token.Position) bool {
+	return position == token.Position{}
+}
+
+// before reports whether position a comes before position b. The zero
+// position marks the end of the input and comes after everything else.
+func before(a token.Position, b token.Position) bool {
+	if isEnd(b) {
+		return true
+	}
+	return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+}
@@ -0,0 +1,249 @@
+package format
+
+import (
+	"monkey/lexer"
+	"monkey/parser"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSource(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"",
+			"",
+		},
+		{
+			"let x=5",
+			"let x = 5;\n",
+		},
+		{
+			`let s="a\tb" ; let h={"k":null,1:[s] , };h.k?.x ?? h?[1]`,
+			"let s = \"a\\tb\";\nlet h = {\"k\": null, 1: [s]};\nh.k?.x ?? h?[1];\n",
+		},
+		{
+			"(a.b).c; (-a).b; (a ?? b) ?? c; a ?? (b ?? c); (f(x)).y",
+			"a.b.c;\n(-a).b;\na ?? b ?? c;\na ?? (b ?? c);\nf(x).y;\n",
+		},
+		{
+			"fn add(a,b){return a+b};;add(1,2)",
+			"fn add(a, b) {\n\treturn a + b;\n}\nadd(1, 2);\n",
+		},
+		{
+			"return x",
+			"return x;\n",
+		},
+		{
+			"let x=1.50*2.0",
+			"let x = 1.5 * 2.0;\n",
+		},
+		{
+			"let a=(b?c:d)?e:(f?g:h); (a?b:c)+1; a ?? b?c:d",
+			"let a = (b ? c : d) ? e : f ? g : h;\n(a ? b : c) + 1;\na ?? b ? c : d;\n",
+		},
+		{
+			`let s=match(x){1=>"one",-1=>"minus one",[a,...rest]=>rest,{"k":[_],name}=>name,_=>{let y=x;y}}`,
+			"let s = match (x) {\n\t1 => \"one\",\n\t-1 => \"minus one\",\n\t[a, ...rest] => rest,\n\t{\"k\": [_], name} => name,\n\t_ => {\n\t\tlet y = x;\n\t\ty;\n\t},\n};\n",
+		},
+		{
+			`let x = match (1) { _ => ({"a": 1}) };`,
+			"let x = match (1) {\n\t_ => ({\"a\": 1}),\n};\n",
+		},
+		{
+			"match(x){}",
+			"match (x) {};\n",
+		},
+		{
+			`import "./lib.mk"as lib  export let a=lib.a;export fn f(){a}`,
+			"import \"./lib.mk\" as lib;\nexport let a = lib.a;\nexport fn f() {\n\ta;\n}\n",
+		},
+		{
+			`let [a,_,...rest]=x;let {name,"age":[age]}=y;fn f({c},[a,b]=[1,2]){a}`,
+			"let [a, _, ...rest] = x;\nlet {name, \"age\": [age]} = y;\nfn f({c}, [a, b] = [1, 2]) {\n\ta;\n}\n",
+		},
+		{
+			"let a = 1; let b = 2;",
+			"let a = 1;\nlet b = 2;\n",
+		},
+		{
+			"((a + b)) * c",
+			"(a + b) * c;\n",
+		},
+		{
+			"(a * b) + c",
+			"a * b + c;\n",
+		},
+		{
+			"a - (b - c)",
+			"a - (b - c);\n",
+		},
+		{
+			"(a - b) - c",
+			"a - b - c;\n",
+		},
+		{
+			"(a < b) == (c > d)",
+			"a < b == c > d;\n",
+		},
+		{
+			"a == (b == c)",
+			"a == (b == c);\n",
+		},
+		{
+			"-(a + b) * !(c)",
+			"-(a + b) * !c;\n",
+		},
+		{
+			"-(-a); !(!a)",
+			"-(-a);\n!!a;\n",
+		},
+		{
+			"(-f)(x); (f)(x); (f(x))(y)",
+			"(-f)(x);\nf(x);\nf(x)(y);\n",
+		},
+		{
+			"fn(a,b){a+b}(1,2)",
+			"fn(a, b) {\n\ta + b;\n}(1, 2);\n",
+		},
+		{
+			"let f = fn() {};",
+			"let f = fn() {};\n",
+		},
+		{
+			"let f = fn(a,b=(1+2),...c) {};",
+			"let f = fn(a, b = 1 + 2, ...c) {};\n",
+		},
+		{
+			"[1,(2),[3]][0]; (f(x))[0]; (a[0])(1); (-a)[0]; -(a[0]);",
+			"[1, 2, [3]][0];\nf(x)[0];\na[0](1);\n(-a)[0];\n-a[0];\n",
+		},
+		{
+			"if (a) { b } else { if (c) { return d; } }",
+			"if (a) {\n\tb;\n} else {\n\tif (c) {\n\t\treturn d;\n\t}\n}\n",
+		},
+		{
+			"try{f()}catch(e){throw e}finally{g()}",
+			"try {\n\tf();\n} catch (e) {\n\tthrow e;\n} finally {\n\tg();\n}\n",
+		},
+		{
+			"let a = 1;\n\n\n\nlet b = 2;\nlet c = 3;",
+			"let a = 1;\n\nlet b = 2;\nlet c = 3;\n",
+		},
+		{
+			"if (a) {\n\n  b;\n\n  c;\n\n}",
+			"if (a) {\n\tb;\n\n\tc;\n}\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			actual, err := Source(testCase.input)
+
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.expected, actual)
+		})
+	}
+}
+
+func TestSourceComments(t *testing.T) {
+	input := `// header
+
+// adds numbers
+let add = fn(a, b) { // inline
+  // explain
+  a + b // sum
+}; // after
+
+if (x) {
+  // empty
+
+}   
+// end`
+
+	expected := `// header
+
+// adds numbers
+let add = fn(a, b) {
+	// inline
+	// explain
+	a + b; // sum
+}; // after
+
+if (x) {
+	// empty
+}
+// end
+`
+
+	actual, err := Source(input)
+
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestSourceIsIdempotent(t *testing.T) {
+	inputs := []string{
+		"let add = fn(a,b){ return a+b }  // inline\n\n\nlet x = (1 + 2) * 3 - (4 - 5);   let y = -(x + 1);",
+		"if (x > y) { add(x, (y)) } else {\n  // nothing to do\n\n}\n// the end",
+		"let f = fn() {\n  let g = fn(x) { x };\n\n  // trailing\n};\nf()(1)",
+		"fn f(a,b=1){ a+b } // add\nfn g(){}",
+		"import \"lib.mk\" as lib // math\n\n// exported\nexport let x = lib.x; // x",
+		"match (x) {\n  // first\n  1 => a, // one\n\n  [b] => { b } // array\n  // last\n}",
+		`let x = match (1) { _ => ({"a": 1}) };`,
+		`match (x) { 1 => ({"a": 1}).a, 2 => ({"a": 1})["a"] + 1, _ => ({} ?? 1) ? 2 : 3 }`,
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			once, err := Source(input)
+			assert.Nil(t, err)
+
+			twice, err := Source(once)
+			assert.Nil(t, err)
+
+			assert.Equal(t, once, twice)
+		})
+	}
+}
+
+func TestSourceKeepsSemantics(t *testing.T) {
+	inputs := []string{
+		"(a + b) * (c - d) / -e",
+		"a - (b + c) - (d * e)",
+		"!(a == b) != (c < d)",
+		"f(g)(h(1 + 2))",
+		"(f(x))[0](-a[1])",
+		"a?.b.c?[d ?? e] ?? (f == g)",
+		"(a ? b : c) ? (d ? e : f) : (g ? h : i)",
+		"-(match (a) { _ => b }) + (a ? [b] : c)",
+		`match (a) { 1 => ({"a": 1}), _ => {} }`,
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			_, expected := parser.New(lexer.New(input)).ParseProgram()
+
+			formatted, err := Source(input)
+			assert.Nil(t, err)
+
+			_, actual := parser.New(lexer.New(formatted)).ParseProgram()
+			assert.Equal(t, expected.String(), actual.String())
+		})
+	}
+}
+
+func TestSourceParserErrors(t *testing.T) {
+	_, err := Source("let = 5;")
+
+	assert.EqualError(t, err, "expected next token to be IDENT, got = instead")
+}
+
+func TestProgram(t *testing.T) {
+	_, program := parser.New(lexer.New("let a = 1;\n\nif (a) { a }")).ParseProgram()
+
+	assert.Equal(t, "let a = 1;\nif (a) {\n\ta;\n}\n", Program(program))
+}
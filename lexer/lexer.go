@@ -10,18 +10,84 @@ type Lexer struct {
 	position     int  // current position in input (points to current char)
 	readPosition int  // current reading position in input (after current char)
 	char         byte // current char under examination
+	line         int  // line of current char
+	column       int  // column of current char
+	lineOffsets  []int
 }
 
 func New(input string) *Lexer {
-	lexer := &Lexer{input: input}
+	lexer := &Lexer{input: input, line: 1, lineOffsets: []int{0}}
 	lexer.readChar()
 	return lexer
 }
 
+// Text returns the input from start up to, but not including, end. Both
+// positions must be within the input read so far.
+func (lexer *Lexer) Text(start token.Position, end token.Position) string {
+	return lexer.input[lexer.offset(start):lexer.offset(end)]
+}
+
+func (lexer *Lexer) offset(position token.Position) int {
+	offset := lexer.lineOffsets[position.Line-1] + position.Column - 1
+	return min(offset, len(lexer.input))
+}
+
+// Copy returns a lexer which continues at the position of lexer, so tokens
+// can be read ahead without consuming them.
+func (lexer *Lexer) Copy() *Lexer {
+	copied := *lexer
+	copied.lineOffsets = slices.Clone(lexer.lineOffsets)
+	return &copied
+}
+
 func (lexer *Lexer) GetNextToken() token.Token {
 	lexer.skipWhitespace()
 
+	position := token.Position{Line: lexer.line, Column: lexer.column}
+	tok := lexer.readToken()
+	tok.Position = position
+
+	return tok
+}
+
+func (lexer *Lexer) readToken() token.Token {
 	nextChar := lexer.peakChar()
+
+	if lexer.char == '/' && nextChar == '/' {
+		return token.Token{
+			Type:    token.COMMENT,
+			Literal: lexer.readComment(),
+		}
+	}
+
+	if lexer.char == '"' {
+		literal, terminated := lexer.readString()
+		if !terminated {
+			return token.Token{
+				Type:    token.ILLEGAL,
+				Literal: `"` + literal,
+			}
+		}
+
+		return token.Token{
+			Type:    token.STRING,
+			Literal: literal,
+		}
+	}
+
+	threeCharLiteral := lexer.peakChars(3)
+
+	if tokenType, ok := token.LookupThreeCharToken(threeCharLiteral); ok {
+		lexer.readChar()
+		lexer.readChar()
+		lexer.readChar()
+
+		return token.Token{
+			Type:    tokenType,
+			Literal: threeCharLiteral,
+		}
+	}
+
 	twoCharLiteral := string(lexer.char) + string(nextChar)
 
 	if tokenType, ok := token.LookupTwoCharToken(twoCharLiteral); ok {
@@ -60,8 +126,7 @@ func (lexer *Lexer) GetNextToken() token.Token {
 	}
 
 	if isDigit(lexer.char) {
-		tokenLiteral := lexer.readNumber()
-		tokenType := token.INT
+		tokenLiteral, tokenType := lexer.readNumber()
 
 		return token.Token{
 			Type:    tokenType,
@@ -78,6 +143,13 @@ func (lexer *Lexer) GetNextToken() token.Token {
 }
 
 func (lexer *Lexer) readChar() {
+	if lexer.char == '\n' {
+		lexer.line += 1
+		lexer.column = 0
+		lexer.lineOffsets = append(lexer.lineOffsets, lexer.readPosition)
+	}
+	lexer.column += 1
+
 	if lexer.readPosition >= len(lexer.input) {
 		lexer.char = 0
 	} else {
@@ -94,6 +166,15 @@ func (lexer *Lexer) peakChar() byte {
 	return lexer.input[lexer.readPosition]
 }
 
+// peakChars returns up to count chars starting at the current char.
+func (lexer *Lexer) peakChars(count int) string {
+	end := min(lexer.position+count, len(lexer.input))
+	if lexer.position >= end {
+		return ""
+	}
+	return lexer.input[lexer.position:end]
+}
+
 func (lexer *Lexer) readIdentifier() string {
 	position := lexer.position
 
@@ -104,16 +185,60 @@ func (lexer *Lexer) readIdentifier() string {
 	return lexer.input[position:lexer.position]
 }
 
-func (lexer *Lexer) readNumber() string {
+func (lexer *Lexer) readComment() string {
 	position := lexer.position
 
-	for isDigit(lexer.char) {
+	for lexer.char != '\n' && lexer.char != 0 {
 		lexer.readChar()
 	}
 
 	return lexer.input[position:lexer.position]
 }
 
+// readString reads a string starting at the opening quote and returns the
+// text between the quotes. Escape sequences are kept as they are.
+func (lexer *Lexer) readString() (literal string, terminated bool) {
+	lexer.readChar()
+	position := lexer.position
+
+	for lexer.char != '"' {
+		if lexer.char == 0 {
+			return lexer.input[position:lexer.position], false
+		}
+		if lexer.char == '\\' && lexer.peakChar() != 0 {
+			lexer.readChar()
+		}
+		lexer.readChar()
+	}
+
+	literal = lexer.input[position:lexer.position]
+	lexer.readChar()
+
+	return literal, true
+}
+
+// readNumber reads an integer or a float. A dot only starts the fraction if
+// a digit follows it, so 1.a is a member access.
+func (lexer *Lexer) readNumber() (string, token.TokenType) {
+	position := lexer.position
+	tokenType := token.INT
+
+	for isDigit(lexer.char) {
+		lexer.readChar()
+	}
+
+	if lexer.char == '.' && isDigit(lexer.peakChar()) {
+		tokenType = token.FLOAT
+		lexer.readChar()
+
+		for isDigit(lexer.char) {
+			lexer.readChar()
+		}
+	}
+
+	return lexer.input[position:lexer.position], tokenType
+}
+
 func (lexer *Lexer) skipWhitespace() {
 	for isWhitespace(lexer.char) {
 		lexer.readChar()
@@ -117,11 +117,247 @@ func TestNextToken(t *testing.T) {
 
 	for _, result := range results {
 		actualToken := lexer.GetNextToken()
-		expectedToken := token.Token{
-			Type:    result.expectedType,
-			Literal: result.expectedLiteral,
-		}
 
-		assert.Equal(t, expectedToken, actualToken, "Tokens should be equal.")
+		assert.Equal(t, result.expectedType, actualToken.Type, "Token types should be equal.")
+		assert.Equal(t, result.expectedLiteral, actualToken.Literal, "Token literals should be equal.")
+	}
+}
+
+func TestTokenPositions(t *testing.T) {
+	input := `let five = 5;
+let add = fn(x, y) {
+	x == y;
+};`
+
+	results := []struct {
+		expectedLiteral  string
+		expectedPosition token.Position
+	}{
+		{"let", token.Position{Line: 1, Column: 1}},
+		{"five", token.Position{Line: 1, Column: 5}},
+		{"=", token.Position{Line: 1, Column: 10}},
+		{"5", token.Position{Line: 1, Column: 12}},
+		{";", token.Position{Line: 1, Column: 13}},
+		{"let", token.Position{Line: 2, Column: 1}},
+		{"add", token.Position{Line: 2, Column: 5}},
+		{"=", token.Position{Line: 2, Column: 9}},
+		{"fn", token.Position{Line: 2, Column: 11}},
+		{"(", token.Position{Line: 2, Column: 13}},
+		{"x", token.Position{Line: 2, Column: 14}},
+		{",", token.Position{Line: 2, Column: 15}},
+		{"y", token.Position{Line: 2, Column: 17}},
+		{")", token.Position{Line: 2, Column: 18}},
+		{"{", token.Position{Line: 2, Column: 20}},
+		{"x", token.Position{Line: 3, Column: 2}},
+		{"==", token.Position{Line: 3, Column: 4}},
+		{"y", token.Position{Line: 3, Column: 7}},
+		{";", token.Position{Line: 3, Column: 8}},
+		{"}", token.Position{Line: 4, Column: 1}},
+		{";", token.Position{Line: 4, Column: 2}},
+		{"", token.Position{Line: 4, Column: 3}},
+	}
+
+	lexer := New(input)
+
+	for _, result := range results {
+		actualToken := lexer.GetNextToken()
+
+		assert.Equal(t, result.expectedLiteral, actualToken.Literal)
+		assert.Equal(t, result.expectedPosition, actualToken.Position)
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// leading
+let a = 10 / 2; // trailing
+//`
+
+	results := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.COMMENT, "// leading"},
+		{token.LET, "let"},
+		{token.IDENT, "a"},
+		{token.ASSIGN, "="},
+		{token.INT, "10"},
+		{token.SLASH, "/"},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.COMMENT, "// trailing"},
+		{token.COMMENT, "//"},
+		{token.EOF, ""},
+	}
+
+	lexer := New(input)
+
+	for _, result := range results {
+		actualToken := lexer.GetNextToken()
+
+		assert.Equal(t, result.expectedType, actualToken.Type)
+		assert.Equal(t, result.expectedLiteral, actualToken.Literal)
+	}
+}
+
+func TestArraysAndRestParameters(t *testing.T) {
+	input := `fn(a, ...rest) { [a, rest][0] }`
+
+	results := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.FUNCTION, "fn"},
+		{token.LPAREN, "("},
+		{token.IDENT, "a"},
+		{token.COMMA, ","},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "rest"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.LBRACKET, "["},
+		{token.IDENT, "a"},
+		{token.COMMA, ","},
+		{token.IDENT, "rest"},
+		{token.RBRACKET, "]"},
+		{token.LBRACKET, "["},
+		{token.INT, "0"},
+		{token.RBRACKET, "]"},
+		{token.RBRACE, "}"},
+		{token.EOF, ""},
+	}
+
+	lexer := New(input)
+
+	for _, result := range results {
+		actualToken := lexer.GetNextToken()
+
+		assert.Equal(t, result.expectedType, actualToken.Type)
+		assert.Equal(t, result.expectedLiteral, actualToken.Literal)
+	}
+}
+
+func TestText(t *testing.T) {
+	input := "let a = 1;\nlet add = fn(x) {\n\tx\n};"
+
+	lexer := New(input)
+	for lexer.GetNextToken().Type != token.EOF {
+	}
+
+	assert.Equal(t, "let a", lexer.Text(token.Position{Line: 1, Column: 1}, token.Position{Line: 1, Column: 6}))
+	assert.Equal(t, "fn(x) {\n\tx\n}", lexer.Text(token.Position{Line: 2, Column: 11}, token.Position{Line: 4, Column: 2}))
+	assert.Equal(t, ";", lexer.Text(token.Position{Line: 4, Column: 2}, token.Position{Line: 4, Column: 10}))
+}
+
+func TestStringsAndNull(t *testing.T) {
+	input := `"foo bar" "a \"b\" \\" "" null ?? a?.b?[0] {"key": x.y} "open`
+
+	results := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.STRING, "foo bar"},
+		{token.STRING, `a \"b\" \\`},
+		{token.STRING, ""},
+		{token.NULL, "null"},
+		{token.NULLISH, "??"},
+		{token.IDENT, "a"},
+		{token.QUESTION_DOT, "?."},
+		{token.IDENT, "b"},
+		{token.QUESTION_BRACKET, "?["},
+		{token.INT, "0"},
+		{token.RBRACKET, "]"},
+		{token.LBRACE, "{"},
+		{token.STRING, "key"},
+		{token.COLON, ":"},
+		{token.IDENT, "x"},
+		{token.DOT, "."},
+		{token.IDENT, "y"},
+		{token.RBRACE, "}"},
+		{token.ILLEGAL, `"open`},
+		{token.EOF, ""},
+	}
+
+	lexer := New(input)
+
+	for _, result := range results {
+		actualToken := lexer.GetNextToken()
+
+		assert.Equal(t, result.expectedType, actualToken.Type)
+		assert.Equal(t, result.expectedLiteral, actualToken.Literal)
+	}
+}
+
+func TestTernaryAndMatch(t *testing.T) {
+	input := `a ? b : c; match (x) { [_, ...r] => r }`
+
+	results := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.QUESTION, "?"},
+		{token.IDENT, "b"},
+		{token.COLON, ":"},
+		{token.IDENT, "c"},
+		{token.SEMICOLON, ";"},
+		{token.MATCH, "match"},
+		{token.LPAREN, "("},
+		{token.IDENT, "x"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.LBRACKET, "["},
+		{token.IDENT, "_"},
+		{token.COMMA, ","},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "r"},
+		{token.RBRACKET, "]"},
+		{token.ARROW, "=>"},
+		{token.IDENT, "r"},
+		{token.RBRACE, "}"},
+		{token.EOF, ""},
+	}
+
+	lexer := New(input)
+
+	for _, result := range results {
+		actualToken := lexer.GetNextToken()
+
+		assert.Equal(t, result.expectedType, actualToken.Type)
+		assert.Equal(t, result.expectedLiteral, actualToken.Literal)
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	input := `1.5 10.25 2. 3.a [4][0].b`
+
+	results := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.FLOAT, "1.5"},
+		{token.FLOAT, "10.25"},
+		{token.INT, "2"},
+		{token.DOT, "."},
+		{token.INT, "3"},
+		{token.DOT, "."},
+		{token.IDENT, "a"},
+		{token.LBRACKET, "["},
+		{token.INT, "4"},
+		{token.RBRACKET, "]"},
+		{token.LBRACKET, "["},
+		{token.INT, "0"},
+		{token.RBRACKET, "]"},
+		{token.DOT, "."},
+		{token.IDENT, "b"},
+		{token.EOF, ""},
+	}
+
+	lexer := New(input)
+
+	for _, result := range results {
+		actualToken := lexer.GetNextToken()
+
+		assert.Equal(t, result.expectedType, actualToken.Type)
+		assert.Equal(t, result.expectedLiteral, actualToken.Literal)
 	}
 }
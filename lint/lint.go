@@ -0,0 +1,114 @@
+package lint
+
+import (
+	"fmt"
+	"monkey/ast"
+	"monkey/token"
+	"slices"
+	"sort"
+)
+
+// A Rule checks a program for one kind of problem.
+type Rule struct {
+	Name        string
+	Description string
+	Check       func(pass *Pass)
+}
+
+// Rules holds all available rules, they are enabled by default.
+var Rules = []*Rule{
+	UnusedLet,
+	UnusedImport,
+	UnusedParameter,
+	Unreachable,
+	Shadow,
+	SelfComparison,
+	ConstantCondition,
+	ArgumentCount,
+	ExhaustiveMatch,
+}
+
+// A Diagnostic describes a problem found by a rule.
+type Diagnostic struct {
+	Position token.Position
+	Rule     string
+	Message  string
+}
+
+func (diagnostic Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s (%s)", diagnostic.Position, diagnostic.Message, diagnostic.Rule)
+}
+
+// A Pass provides a rule with the program to check and collects the
+// problems it reports.
+type Pass struct {
+	Program *ast.Program
+
+	rule        *Rule
+	scopes      *resolver
+	diagnostics []Diagnostic
+}
+
+// Report records a problem at the given position.
+func (pass *Pass) Report(position token.Position, format string, a ...any) {
+	pass.diagnostics = append(pass.diagnostics, Diagnostic{
+		Position: position,
+		Rule:     pass.rule.Name,
+		Message:  fmt.Sprintf(format, a...),
+	})
+}
+
+// Lint runs the given rules on program and returns the reported problems
+// ordered by position.
+func Lint(program *ast.Program, rules []*Rule) []Diagnostic {
+	pass := &Pass{
+		Program: program,
+		scopes:  resolve(program),
+	}
+
+	for _, rule := range rules {
+		pass.rule = rule
+		rule.Check(pass)
+	}
+
+	sort.SliceStable(pass.diagnostics, func(i, j int) bool {
+		a, b := pass.diagnostics[i].Position, pass.diagnostics[j].Position
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	})
+
+	return pass.diagnostics
+}
+
+// Select returns the rules named in enable, or all rules if enable is empty,
+// without the ones named in disable.
+func Select(enable []string, disable []string) ([]*Rule, error) {
+	byName := map[string]*Rule{}
+	for _, rule := range Rules {
+		byName[rule.Name] = rule
+	}
+
+	for _, name := range append(enable, disable...) {
+		if _, ok := byName[name]; !ok {
+			return nil, fmt.Errorf("unknown rule %q", name)
+		}
+	}
+
+	selected := Rules
+	if len(enable) > 0 {
+		selected = []*Rule{}
+		for _, rule := range Rules {
+			if slices.Contains(enable, rule.Name) {
+				selected = append(selected, rule)
+			}
+		}
+	}
+
+	rules := []*Rule{}
+	for _, rule := range selected {
+		if !slices.Contains(disable, rule.Name) {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,255 @@
+package lint
+
+import (
+	"monkey/lexer"
+	"monkey/parser"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func runLint(t *testing.T, input string, rules ...*Rule) []string {
+	errors, program := parser.New(lexer.New(input)).ParseProgram()
+	assert.Nil(t, errors)
+
+	diagnostics := []string{}
+	for _, diagnostic := range Lint(program, rules) {
+		diagnostics = append(diagnostics, diagnostic.String())
+	}
+	return diagnostics
+}
+
+func TestRules(t *testing.T) {
+	testCases := []struct {
+		rule     *Rule
+		input    string
+		expected []string
+	}{
+		{
+			UnusedLet,
+			"let a = 1; let b = 2; let _c = 3; b;",
+			[]string{"1:5: a declared but never used (unused-let)"},
+		},
+		{
+			UnusedLet,
+			"let f = fn() { g() }; let g = fn() { 1 }; f();",
+			[]string{},
+		},
+		{
+			UnusedLet,
+			"let x = 1; let x = x + 1; x;",
+			[]string{},
+		},
+		{
+			UnusedLet,
+			"let x = 1; if (true) { let x = 2; }; x;",
+			[]string{"1:28: x declared but never used (unused-let)"},
+		},
+		{
+			UnusedLet,
+			"fn f() { x }; let x = 1; f();",
+			[]string{},
+		},
+		{
+			Unreachable,
+			"fn f() { return g(); fn g() { 1 } }; f();",
+			[]string{},
+		},
+		{
+			Unreachable,
+			"fn f() { return 1; fn g() { 1 } 2; }; f();",
+			[]string{"1:33: unreachable code (unreachable)"},
+		},
+		{
+			Unreachable,
+			"fn f() { throw 1; 2; }; f();",
+			[]string{"1:19: unreachable code (unreachable)"},
+		},
+		{
+			ArgumentCount,
+			"even(1, 2); fn even(n) { odd(n) } fn odd(n) { even() }",
+			[]string{
+				"1:1: even expects 1 arguments, got 2 (argument-count)",
+				"1:47: even expects 1 arguments, got 0 (argument-count)",
+			},
+		},
+		{
+			UnusedParameter,
+			"let f = fn(a, b, _c) { a }; f(1, 2, 3);",
+			[]string{"1:15: parameter b is never used (unused-parameter)"},
+		},
+		{
+			Unreachable,
+			"let f = fn() { return 1; 2; 3; }; return f(); f;",
+			[]string{
+				"1:26: unreachable code (unreachable)",
+				"1:47: unreachable code (unreachable)",
+			},
+		},
+		{
+			Shadow,
+			"let a = 1; let f = fn(a) { let b = a; if (b) { let b = 2; b } }; let a = 3;",
+			[]string{
+				"1:23: a shadows declaration at 1:5 (shadow)",
+				"1:52: b shadows declaration at 1:32 (shadow)",
+			},
+		},
+		{
+			SelfComparison,
+			"a == a; a != a; a < b; f() == f(); -a > -a;",
+			[]string{
+				"1:3: comparison of a with itself is always true (self-comparison)",
+				"1:11: comparison of a with itself is always false (self-comparison)",
+				"1:39: comparison of (-a) with itself is always false (self-comparison)",
+			},
+		},
+		{
+			ConstantCondition,
+			"if (true) { 1 }; if (1 < 2) { 1 }; if (a) { 1 }; if (f()) { 1 };",
+			[]string{
+				"1:1: if condition true is constant (constant-condition)",
+				"1:18: if condition (1 < 2) is constant (constant-condition)",
+			},
+		},
+		{
+			ConstantCondition,
+			`if (null ?? "a") { 1 }; if (h.a) { 1 };`,
+			[]string{
+				`1:1: if condition (null ?? "a") is constant (constant-condition)`,
+			},
+		},
+		{
+			UnusedLet,
+			`let h = {"a": 1}; let a = 2; let b = 3; h.a + h?[b];`,
+			[]string{"1:23: a declared but never used (unused-let)"},
+		},
+		{
+			ArgumentCount,
+			"let add = fn(a, b) { a + b }; add(1); add(1, 2); add(1, 2, 3); fn(x) { x }(); unknown(1);",
+			[]string{
+				"1:31: add expects 2 arguments, got 1 (argument-count)",
+				"1:50: add expects 2 arguments, got 3 (argument-count)",
+				"1:75: function expects 1 arguments, got 0 (argument-count)",
+			},
+		},
+		{
+			ArgumentCount,
+			"let f = fn(a, b = 1) { a }; f(); f(1); f(1, 2, 3); let g = fn(a, ...b) { a }; g(); g(1, 2, 3);",
+			[]string{
+				"1:29: f expects at least 1 arguments, got 0 (argument-count)",
+				"1:40: f expects at most 2 arguments, got 3 (argument-count)",
+				"1:79: g expects at least 1 arguments, got 0 (argument-count)",
+			},
+		},
+		{
+			UnusedParameter,
+			"let f = fn(a, b = a) { b }; f(1);",
+			[]string{},
+		},
+		{
+			UnusedLet,
+			"let a = 1; let b = 2; match (a) { [b] => b, _ => 0 };",
+			[]string{"1:16: b declared but never used (unused-let)"},
+		},
+		{
+			UnusedLet,
+			"let a = 1; let b = 2; match (a) { [c] => c, _ => { b } } ? a : 0;",
+			[]string{},
+		},
+		{
+			Shadow,
+			"let a = 1; match (a) { {a} => a, [x] => x };",
+			[]string{"1:25: a shadows declaration at 1:5 (shadow)"},
+		},
+		{
+			ConstantCondition,
+			"if (true ? a : b) { 1 }; if (1 > 2 ? 3 : 4) { 1 }",
+			[]string{"1:26: if condition ((1 > 2) ? 3 : 4) is constant (constant-condition)"},
+		},
+		{
+			UnusedLet,
+			"let [a, _b, ...c] = x; let {d} = y; a + d;",
+			[]string{"1:16: c declared but never used (unused-let)"},
+		},
+		{
+			UnusedParameter,
+			"let f = fn([a, b], {c}) { a }; f([1, 2], {});",
+			[]string{
+				"1:16: parameter b is never used (unused-parameter)",
+				"1:21: parameter c is never used (unused-parameter)",
+			},
+		},
+		{
+			Shadow,
+			"let a = 1; let f = fn([a]) { a }; f([a]);",
+			[]string{"1:24: a shadows declaration at 1:5 (shadow)"},
+		},
+		{
+			UnusedImport,
+			`import "a.mk" as a; import "b.mk" as b; import "c.mk" as _c; b.x;`,
+			[]string{"1:18: module a imported but never used (unused-import)"},
+		},
+		{
+			UnusedLet,
+			"let a = 1; export let b = a; export let [c, d] = [1, 2];",
+			[]string{},
+		},
+		{
+			Unreachable,
+			"return 1; export fn f() { 1 } export let a = 1;",
+			[]string{"1:31: unreachable code (unreachable)"},
+		},
+		{
+			ExhaustiveMatch,
+			"let a = true; match (a) { true => 1 }; match (a) { false => 1 }; match (a) { true => 1, false => 2 };",
+			[]string{
+				"1:15: match is not exhaustive, missing false (exhaustive-match)",
+				"1:40: match is not exhaustive, missing true (exhaustive-match)",
+			},
+		},
+		{
+			ExhaustiveMatch,
+			"let a = true; match (a) { true => 1, _ => 2 }; match (a) { true => 1, x => x }; match (a) { 1 => 1 }; match (a) {};",
+			[]string{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.rule.Name+" "+testCase.input, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, runLint(t, testCase.input, testCase.rule))
+		})
+	}
+}
+
+func TestLintOrdersByPosition(t *testing.T) {
+	input := `let f = fn(x) {
+	return 1;
+	x == x;
+};`
+
+	expected := []string{
+		"1:5: f declared but never used (unused-let)",
+		"3:2: unreachable code (unreachable)",
+		"3:4: comparison of x with itself is always true (self-comparison)",
+	}
+
+	assert.Equal(t, expected, runLint(t, input, Rules...))
+}
+
+func TestSelect(t *testing.T) {
+	rules, err := Select(nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, Rules, rules)
+
+	rules, err = Select([]string{"shadow", "unreachable"}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []*Rule{Unreachable, Shadow}, rules)
+
+	rules, err = Select(nil, []string{"shadow"})
+	assert.Nil(t, err)
+	assert.NotContains(t, rules, Shadow)
+	assert.Len(t, rules, len(Rules)-1)
+
+	_, err = Select([]string{"nope"}, nil)
+	assert.EqualError(t, err, `unknown rule "nope"`)
+}
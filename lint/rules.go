@@ -0,0 +1,282 @@
+package lint
+
+import (
+	"monkey/ast"
+	"monkey/token"
+	"strings"
+)
+
+var UnusedLet = &Rule{
+	Name:        "unused-let",
+	Description: "let bindings that are never used",
+	Check: func(pass *Pass) {
+		for _, binding := range pass.scopes.bindings {
+			if binding.kind == letBinding && !binding.used && !isIgnored(binding) {
+				pass.Report(binding.identifier.Token.Position, "%s declared but never used", binding.identifier.Value)
+			}
+		}
+	},
+}
+
+var UnusedImport = &Rule{
+	Name:        "unused-import",
+	Description: "imported modules that are never used",
+	Check: func(pass *Pass) {
+		for _, binding := range pass.scopes.bindings {
+			if binding.kind == importBinding && !binding.used && !isIgnored(binding) {
+				pass.Report(binding.identifier.Token.Position, "module %s imported but never used", binding.identifier.Value)
+			}
+		}
+	},
+}
+
+var UnusedParameter = &Rule{
+	Name:        "unused-parameter",
+	Description: "function parameters that are never used",
+	Check: func(pass *Pass) {
+		for _, binding := range pass.scopes.bindings {
+			if binding.kind == parameterBinding && !binding.used && !isIgnored(binding) {
+				pass.Report(binding.identifier.Token.Position, "parameter %s is never used", binding.identifier.Value)
+			}
+		}
+	},
+}
+
+// isIgnored reports whether a binding is marked as intentionally unused by
+// a leading underscore.
+func isIgnored(binding *binding) bool {
+	return strings.HasPrefix(binding.identifier.Value, "_")
+}
+
+var Unreachable = &Rule{
+	Name:        "unreachable",
+	Description: "statements after a return or throw statement",
+	Check: func(pass *Pass) {
+		check := func(statements []ast.Statement) {
+			returned := false
+
+			for _, statement := range statements {
+				switch node := statement.(type) {
+				case *ast.ReturnStatement, *ast.ThrowStatement:
+					if !returned {
+						returned = true
+						continue
+					}
+				case *ast.FunctionStatement:
+					// Function declarations are hoisted, so they are reachable
+					continue
+				case *ast.ExportStatement:
+					if _, ok := node.Statement.(*ast.FunctionStatement); ok {
+						continue
+					}
+				}
+
+				if returned {
+					pass.Report(statementPosition(statement), "unreachable code")
+					return
+				}
+			}
+		}
+
+		ast.Inspect(pass.Program, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.Program:
+				check(node.Statements)
+			case *ast.BlockStatement:
+				check(node.Statements)
+			}
+			return true
+		})
+	},
+}
+
+func statementPosition(statement ast.Statement) token.Position {
+	switch statement := statement.(type) {
+	case *ast.LetStatement:
+		return statement.Token.Position
+	case *ast.FunctionStatement:
+		return statement.Token.Position
+	case *ast.ImportStatement:
+		return statement.Token.Position
+	case *ast.ExportStatement:
+		return statement.Token.Position
+	case *ast.ReturnStatement:
+		return statement.Token.Position
+	case *ast.ThrowStatement:
+		return statement.Token.Position
+	case *ast.ExpressionStatement:
+		return statement.Token.Position
+	case *ast.BlockStatement:
+		return statement.Token.Position
+	}
+	return token.Position{}
+}
+
+var Shadow = &Rule{
+	Name:        "shadow",
+	Description: "bindings that hide a binding of an outer scope",
+	Check: func(pass *Pass) {
+		for _, binding := range pass.scopes.bindings {
+			if binding.shadows != nil {
+				pass.Report(
+					binding.identifier.Token.Position,
+					"%s shadows declaration at %s",
+					binding.identifier.Value,
+					binding.shadows.identifier.Token.Position,
+				)
+			}
+		}
+	},
+}
+
+var SelfComparison = &Rule{
+	Name:        "self-comparison",
+	Description: "comparisons of a value with itself",
+	Check: func(pass *Pass) {
+		results := map[string]string{
+			"==": "true",
+			"!=": "false",
+			"<":  "false",
+			">":  "false",
+		}
+
+		ast.Inspect(pass.Program, func(node ast.Node) bool {
+			infix, ok := node.(*ast.InfixExpression)
+			if !ok {
+				return true
+			}
+
+			result, isComparison := results[infix.Operator]
+			if isComparison && !hasCall(infix.Left) && infix.Left.String() == infix.Right.String() {
+				pass.Report(infix.Token.Position, "comparison of %s with itself is always %s", infix.Left, result)
+			}
+
+			return true
+		})
+	},
+}
+
+// hasCall reports whether evaluating expression calls a function, which
+// might return a different value on every call.
+func hasCall(expression ast.Expression) bool {
+	found := false
+
+	ast.Inspect(expression, func(node ast.Node) bool {
+		if _, ok := node.(*ast.CallExpression); ok {
+			found = true
+		}
+		return !found
+	})
+
+	return found
+}
+
+var ConstantCondition = &Rule{
+	Name:        "constant-condition",
+	Description: "if conditions that do not depend on any binding",
+	Check: func(pass *Pass) {
+		ast.Inspect(pass.Program, func(node ast.Node) bool {
+			ifExpression, ok := node.(*ast.IfExpression)
+			if ok && ifExpression.Condition != nil && isConstant(ifExpression.Condition) {
+				pass.Report(ifExpression.Token.Position, "if condition %s is constant", ifExpression.Condition)
+			}
+			return true
+		})
+	},
+}
+
+// isConstant reports whether expression is built from literals and
+// operators only.
+func isConstant(expression ast.Expression) bool {
+	constant := true
+
+	ast.Inspect(expression, func(node ast.Node) bool {
+		switch node.(type) {
+		case nil, *ast.IntegerLiteral, *ast.FloatLiteral, *ast.Boolean, *ast.StringLiteral, *ast.NullLiteral,
+			*ast.ArrayLiteral, *ast.HashLiteral, *ast.PrefixExpression, *ast.InfixExpression,
+			*ast.IndexExpression, *ast.ConditionalExpression:
+		default:
+			constant = false
+		}
+		return constant
+	})
+
+	return constant
+}
+
+var ExhaustiveMatch = &Rule{
+	Name:        "exhaustive-match",
+	Description: "matches on booleans that do not handle both true and false",
+	Check: func(pass *Pass) {
+		ast.Inspect(pass.Program, func(node ast.Node) bool {
+			match, ok := node.(*ast.MatchExpression)
+			if !ok || len(match.Arms) == 0 {
+				return true
+			}
+
+			missing := map[bool]bool{true: true, false: true}
+
+			for _, arm := range match.Arms {
+				// Only matches made of boolean literals are checked, any other
+				// pattern either matches everything or cannot be exhausted
+				literal, ok := arm.Pattern.(*ast.LiteralPattern)
+				if !ok {
+					return true
+				}
+				boolean, ok := literal.Value.(*ast.Boolean)
+				if !ok {
+					return true
+				}
+				delete(missing, boolean.Value)
+			}
+
+			if missing[true] {
+				pass.Report(match.Token.Position, "match is not exhaustive, missing true")
+			} else if missing[false] {
+				pass.Report(match.Token.Position, "match is not exhaustive, missing false")
+			}
+
+			return true
+		})
+	},
+}
+
+var ArgumentCount = &Rule{
+	Name:        "argument-count",
+	Description: "calls of directly known functions with the wrong number of arguments",
+	Check: func(pass *Pass) {
+		ast.Inspect(pass.Program, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpression)
+			if !ok {
+				return true
+			}
+
+			function, known := pass.scopes.calls[call]
+			if !known {
+				return true
+			}
+
+			name := "function"
+			position := call.Token.Position
+
+			if identifier, ok := call.Function.(*ast.Identifier); ok {
+				name = identifier.Value
+				position = identifier.Token.Position
+			}
+
+			required, maximum := ast.Arity(function.Parameters)
+			count := len(call.Arguments)
+
+			switch {
+			case required == maximum && count != required:
+				pass.Report(position, "%s expects %d arguments, got %d", name, required, count)
+			case count < required:
+				pass.Report(position, "%s expects at least %d arguments, got %d", name, required, count)
+			case maximum >= 0 && count > maximum:
+				pass.Report(position, "%s expects at most %d arguments, got %d", name, maximum, count)
+			}
+
+			return true
+		})
+	},
+}
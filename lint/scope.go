@@ -0,0 +1,264 @@
+package lint
+
+import (
+	"monkey/ast"
+)
+
+type bindingKind int
+
+const (
+	letBinding bindingKind = iota
+	parameterBinding
+	functionBinding
+	patternBinding
+	importBinding
+)
+
+// A binding is a name introduced by a let statement, a function parameter, a
+// function declaration, a match pattern or an import.
+type binding struct {
+	identifier *ast.Identifier
+	kind       bindingKind
+	used       bool
+	shadows    *binding             // binding of an outer scope with the same name
+	function   *ast.FunctionLiteral // function literal the binding is bound to, if known
+}
+
+type scope struct {
+	outer    *scope
+	bindings map[string]*binding
+	deferred []func()
+}
+
+func newScope(outer *scope) *scope {
+	return &scope{outer: outer, bindings: map[string]*binding{}}
+}
+
+func (scope *scope) lookup(name string) *binding {
+	if binding, ok := scope.bindings[name]; ok {
+		return binding
+	}
+	if scope.outer != nil {
+		return scope.outer.lookup(name)
+	}
+	return nil
+}
+
+// A resolver matches identifiers to the bindings they refer to.
+type resolver struct {
+	bindings []*binding
+	calls    map[*ast.CallExpression]*ast.FunctionLiteral // calls of directly known functions
+}
+
+// resolve follows the scoping of the evaluator: programs, blocks and
+// function calls introduce scopes. Function bodies are resolved at the end
+// of the scope they are defined in, as they can refer to any binding that
+// exists in their environment when they are called.
+func resolve(program *ast.Program) *resolver {
+	resolver := &resolver{
+		calls: map[*ast.CallExpression]*ast.FunctionLiteral{},
+	}
+
+	resolver.statements(program.Statements, newScope(nil))
+
+	return resolver
+}
+
+// declare adds a binding to scope. Declaring a name again in the same scope
+// overwrites its value in the evaluator, so the existing binding is reused.
+func (resolver *resolver) declare(scope *scope, identifier *ast.Identifier, kind bindingKind) *binding {
+	if existing, ok := scope.bindings[identifier.Value]; ok {
+		return existing
+	}
+
+	binding := &binding{identifier: identifier, kind: kind}
+
+	if scope.outer != nil {
+		binding.shadows = scope.outer.lookup(identifier.Value)
+	}
+
+	scope.bindings[identifier.Value] = binding
+	resolver.bindings = append(resolver.bindings, binding)
+
+	return binding
+}
+
+func (resolver *resolver) statements(statements []ast.Statement, scope *scope) {
+	// Function declarations are bound before any statement runs
+	for _, statement := range statements {
+		if export, ok := statement.(*ast.ExportStatement); ok {
+			statement = export.Statement
+		}
+
+		if declaration, ok := statement.(*ast.FunctionStatement); ok && declaration.Name != nil {
+			binding := resolver.declare(scope, declaration.Name, functionBinding)
+			binding.function = declaration.Function
+		}
+	}
+
+	for _, statement := range statements {
+		resolver.statement(statement, scope)
+	}
+
+	for len(scope.deferred) > 0 {
+		resolve := scope.deferred[0]
+		scope.deferred = scope.deferred[1:]
+		resolve()
+	}
+}
+
+func (resolver *resolver) statement(statement ast.Statement, scope *scope) {
+	switch statement := statement.(type) {
+	case *ast.LetStatement:
+		resolver.expression(statement.Value, scope)
+
+		if name, ok := statement.Name.(*ast.Identifier); ok {
+			binding := resolver.declare(scope, name, letBinding)
+			binding.function, _ = statement.Value.(*ast.FunctionLiteral)
+		} else {
+			resolver.pattern(statement.Name, scope, letBinding)
+		}
+
+	case *ast.FunctionStatement:
+		if statement.Function != nil {
+			resolver.expression(statement.Function, scope)
+		}
+
+	case *ast.ImportStatement:
+		if statement.Alias != nil {
+			resolver.declare(scope, statement.Alias, importBinding)
+		}
+
+	case *ast.ExportStatement:
+		resolver.statement(statement.Statement, scope)
+
+		// Importers use exported bindings
+		for _, name := range statement.Names() {
+			if binding := scope.lookup(name.Value); binding != nil {
+				binding.used = true
+			}
+		}
+
+	case *ast.ReturnStatement:
+		resolver.expression(statement.Value, scope)
+
+	case *ast.ThrowStatement:
+		resolver.expression(statement.Value, scope)
+
+	case *ast.ExpressionStatement:
+		resolver.expression(statement.Value, scope)
+
+	case *ast.BlockStatement:
+		resolver.block(statement, scope)
+	}
+}
+
+func (resolver *resolver) block(block *ast.BlockStatement, outer *scope) {
+	if block != nil {
+		resolver.statements(block.Statements, newScope(outer))
+	}
+}
+
+func (resolver *resolver) expression(expression ast.Expression, scope *scope) {
+	switch expression := expression.(type) {
+	case *ast.Identifier:
+		if binding := scope.lookup(expression.Value); binding != nil {
+			binding.used = true
+		}
+
+	case *ast.FunctionLiteral:
+		scope.deferred = append(scope.deferred, func() {
+			callScope := newScope(scope)
+			for _, parameter := range expression.Parameters {
+				// Defaults can refer to the parameters before them
+				resolver.expression(parameter.Default, callScope)
+				resolver.pattern(parameter.Name, callScope, parameterBinding)
+			}
+			resolver.block(expression.Body, callScope)
+		})
+
+	case *ast.CallExpression:
+		resolver.expression(expression.Function, scope)
+
+		switch function := expression.Function.(type) {
+		case *ast.FunctionLiteral:
+			resolver.calls[expression] = function
+		case *ast.Identifier:
+			if binding := scope.lookup(function.Value); binding != nil && binding.function != nil {
+				resolver.calls[expression] = binding.function
+			}
+		}
+
+		for _, argument := range expression.Arguments {
+			resolver.expression(argument, scope)
+		}
+
+	case *ast.ArrayLiteral:
+		for _, element := range expression.Elements {
+			resolver.expression(element, scope)
+		}
+
+	case *ast.IndexExpression:
+		resolver.expression(expression.Left, scope)
+		resolver.expression(expression.Index, scope)
+
+	case *ast.MemberExpression:
+		resolver.expression(expression.Object, scope)
+
+	case *ast.HashLiteral:
+		for _, pair := range expression.Pairs {
+			resolver.expression(pair.Key, scope)
+			resolver.expression(pair.Value, scope)
+		}
+
+	case *ast.IfExpression:
+		resolver.expression(expression.Condition, scope)
+		resolver.block(expression.Consequence, scope)
+		resolver.block(expression.Alternative, scope)
+
+	case *ast.TryExpression:
+		resolver.block(expression.Block, scope)
+
+		if expression.Catch != nil {
+			catchScope := newScope(scope)
+			resolver.declare(catchScope, expression.Parameter, patternBinding)
+			resolver.statements(expression.Catch.Statements, catchScope)
+		}
+
+		resolver.block(expression.Finally, scope)
+
+	case *ast.ConditionalExpression:
+		resolver.expression(expression.Condition, scope)
+		resolver.expression(expression.Consequence, scope)
+		resolver.expression(expression.Alternative, scope)
+
+	case *ast.MatchExpression:
+		resolver.expression(expression.Subject, scope)
+
+		for _, arm := range expression.Arms {
+			armScope := newScope(scope)
+			resolver.pattern(arm.Pattern, armScope, patternBinding)
+
+			switch body := arm.Body.(type) {
+			case *ast.BlockStatement:
+				resolver.statements(body.Statements, armScope)
+			case ast.Expression:
+				resolver.expression(body, armScope)
+			}
+		}
+
+	case *ast.PrefixExpression:
+		resolver.expression(expression.Right, scope)
+
+	case *ast.InfixExpression:
+		resolver.expression(expression.Left, scope)
+		resolver.expression(expression.Right, scope)
+	}
+}
+
+// pattern declares the names bound by pattern in scope.
+func (resolver *resolver) pattern(pattern ast.Pattern, scope *scope, kind bindingKind) {
+	for _, name := range ast.PatternNames(pattern) {
+		resolver.declare(scope, name, kind)
+	}
+}
@@ -2,12 +2,17 @@ package main
 
 import (
 	"fmt"
+	"monkey/cmd"
 	"monkey/repl"
 	"os"
 	"os/user"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(cmd.Run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+	}
+
 	user, err := user.Current()
 	if err != nil {
 		panic(err)
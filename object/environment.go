@@ -1,5 +1,7 @@
 package object
 
+import "sync"
+
 func NewEnvironment() *Environment {
 	return &Environment{
 		store: make(map[string]Object),
@@ -13,13 +15,40 @@ func NewEnclosedEnvironment(outterEnv *Environment) *Environment {
 	}
 }
 
+// An Environment binds names to values. Tasks running on several goroutines
+// can share it, closures capture their environment.
 type Environment struct {
+	mutex     sync.RWMutex
 	store     map[string]Object
 	outterEnv *Environment
+	frozen    bool
+}
+
+// Freeze makes env and the environments enclosing it immutable and returns
+// env. A frozen environment, e.g. a prelude of shared functions, can be read
+// by evaluations on several goroutines, each of them binds its names in an
+// enclosed environment of its own.
+func (env *Environment) Freeze() *Environment {
+	for frozen := env; frozen != nil; frozen = frozen.outterEnv {
+		frozen.mutex.Lock()
+		frozen.frozen = true
+		frozen.mutex.Unlock()
+	}
+	return env
+}
+
+// Frozen reports whether env is immutable.
+func (env *Environment) Frozen() bool {
+	env.mutex.RLock()
+	defer env.mutex.RUnlock()
+
+	return env.frozen
 }
 
 func (env *Environment) Get(key string) (Object, bool) {
+	env.mutex.RLock()
 	object, ok := env.store[key]
+	env.mutex.RUnlock()
 
 	if !ok && env.outterEnv != nil {
 		return env.outterEnv.Get(key)
@@ -28,7 +57,15 @@ func (env *Environment) Get(key string) (Object, bool) {
 	return object, ok
 }
 
+// Set binds key to value in env. It panics if env is frozen.
 func (env *Environment) Set(key string, value Object) Object {
+	env.mutex.Lock()
+	defer env.mutex.Unlock()
+
+	if env.frozen {
+		panic("object: Set of " + key + " in frozen environment")
+	}
+
 	env.store[key] = value
 	return value
 }
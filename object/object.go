@@ -2,20 +2,35 @@ package object
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"hash/fnv"
+	"math/rand"
 	"monkey/ast"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 )
 
 type ObjectType string
 
 const (
 	INTEGER_OBJECT      ObjectType = "INTEGER"
+	FLOAT_OBJECT        ObjectType = "FLOAT"
 	BOOLEAN_OBJECT      ObjectType = "BOOLEAN"
 	NULL_OBJECT         ObjectType = "NULL"
 	RETURN_VALUE_OBJECT ObjectType = "RETURN_VALUE"
 	ERROR_OBJECT        ObjectType = "ERROR"
 	FUNCTION_OBJECT     ObjectType = "FUNCTION"
+	ARRAY_OBJECT        ObjectType = "ARRAY"
+	STRING_OBJECT       ObjectType = "STRING"
+	HASH_OBJECT         ObjectType = "HASH"
+	MODULE_OBJECT       ObjectType = "MODULE"
+	BUILTIN_OBJECT      ObjectType = "BUILTIN"
+	TASK_OBJECT         ObjectType = "TASK"
+	CHANNEL_OBJECT      ObjectType = "CHANNEL"
+	ERROR_VALUE_OBJECT  ObjectType = "ERROR_VALUE"
 )
 
 type Object interface {
@@ -30,6 +45,22 @@ type Integer struct {
 func (integer *Integer) Type() ObjectType { return INTEGER_OBJECT }
 func (integer *Integer) Inspect() string  { return fmt.Sprintf("%d", integer.Value) }
 
+type Float struct {
+	Value float64
+}
+
+func (float *Float) Type() ObjectType { return FLOAT_OBJECT }
+
+// Inspect returns the shortest representation of the value which has a
+// fraction or an exponent, e.g. 2.0 or 1e+21, to tell it from an integer.
+func (float *Float) Inspect() string {
+	literal := strconv.FormatFloat(float.Value, 'g', -1, 64)
+	if !strings.ContainsAny(literal, ".eIN") {
+		literal += ".0"
+	}
+	return literal
+}
+
 type Boolean struct {
 	Value bool
 }
@@ -51,8 +82,26 @@ func (returnValue *ReturnValue) Inspect() string {
 	return returnValue.Value.Inspect()
 }
 
+// An ErrorKind tells apart errors which hosts and scripts handle
+// differently. Errors of the evaluator and of builtins have no kind.
+type ErrorKind string
+
+const (
+	EXIT_ERROR     ErrorKind = "EXIT"     // the script called exit
+	LIMIT_ERROR    ErrorKind = "LIMIT"    // the evaluation exceeded a limit
+	CANCELED_ERROR ErrorKind = "CANCELED" // the context of the evaluation is done
+	MEMORY_ERROR   ErrorKind = "MEMORY"   // the evaluation exceeded its memory limit
+	THROW_ERROR    ErrorKind = "THROW"    // the script threw a value which is no error
+)
+
+// An Error aborts the evaluation until a try expression catches it. Errors
+// are shared, e.g. by all tasks awaiting a failed task, and never changed.
 type Error struct {
 	Message string
+	Kind    ErrorKind
+	Code    int      // exit status of EXIT errors
+	Value   Object   // the value thrown or the data of error(), nil if none
+	Stack   []string // the functions the error passed, the innermost first
 }
 
 func (errorObject *Error) Type() ObjectType { return ERROR_OBJECT }
@@ -60,26 +109,267 @@ func (errorObject *Error) Inspect() string {
 	return "Error: " + errorObject.Message
 }
 
+// An ErrorValue is a caught error. Unlike an Error, it is an ordinary value
+// which does not abort the evaluation.
+type ErrorValue struct {
+	Error *Error
+}
+
+func (errorValue *ErrorValue) Type() ObjectType { return ERROR_VALUE_OBJECT }
+func (errorValue *ErrorValue) Inspect() string {
+	return "error(" + strconv.Quote(errorValue.Error.Message) + ")"
+}
+
 type Function struct {
-	Parameters []*ast.Identifier
+	Name       string // name of declared functions, empty for function literals
+	Parameters []*ast.Parameter
 	Body       *ast.BlockStatement
+	Source     string // source text of the function, empty if unknown
 	Env        *Environment
 }
 
 func (function *Function) Type() ObjectType { return FUNCTION_OBJECT }
-func (function *Function) Inspect() string {
-	var out bytes.Buffer
 
+// Inspect returns the signature of the function, e.g. fn add(a, b).
+func (function *Function) Inspect() string {
 	params := []string{}
 	for _, param := range function.Parameters {
 		params = append(params, param.String())
 	}
 
-	out.WriteString("fn (")
-	out.WriteString(strings.Join(params, ", "))
-	out.WriteString(") {\n")
-	out.WriteString(function.Body.String())
-	out.WriteString("\n}")
+	name := ""
+	if function.Name != "" {
+		name = " " + function.Name
+	}
+
+	return fmt.Sprintf("fn%s(%s)", name, strings.Join(params, ", "))
+}
+
+// Details returns the source text of the function followed by the values
+// of the variables it captured from its environment.
+func (function *Function) Details() string {
+	var out bytes.Buffer
+
+	if function.Source != "" {
+		out.WriteString(function.Source)
+	} else {
+		out.WriteString(function.Inspect() + " " + function.Body.String())
+	}
+
+	literal := &ast.FunctionLiteral{Parameters: function.Parameters, Body: function.Body}
+	captured := []string{}
+
+	for _, name := range ast.FreeVariables(literal) {
+		if value, ok := function.Env.Get(name); ok {
+			captured = append(captured, fmt.Sprintf("\t%s = %s", name, value.Inspect()))
+		}
+	}
+
+	if len(captured) > 0 {
+		out.WriteString("\ncaptured:\n")
+		out.WriteString(strings.Join(captured, "\n"))
+	}
 
 	return out.String()
 }
+
+// A Runtime gives builtins access to the interpreter which calls them.
+type Runtime interface {
+	// Call calls a function or builtin and returns its result, which is an
+	// *Error if the call failed.
+	Call(function Object, arguments ...Object) Object
+
+	// IsTruthy reports whether value counts as true in conditions.
+	IsTruthy(value Object) bool
+
+	// Rand returns the source of random numbers.
+	Rand() *rand.Rand
+
+	// Context returns the context of the evaluation. Builtins which block
+	// return an error once it is done.
+	Context() context.Context
+
+	// Spawn calls a function or builtin on a goroutine of its own.
+	Spawn(function Object, arguments ...Object) *Task
+
+	// Step counts an iteration of a builtin towards the limits of the
+	// evaluation. Builtins which loop by the size of their arguments step
+	// in every iteration and return the error once the evaluation must
+	// stop, e.g. because it timed out.
+	Step() *Error
+
+	// Reserve reports an error if creating count values of size bytes
+	// would exceed the limits of the evaluation. Builtins which create
+	// values by the size of their arguments reserve them before they
+	// create them, they are counted once the builtin returns them.
+	Reserve(count int, size int64) *Error
+}
+
+// A BuiltinFunction implements a builtin in Go. It gets the evaluated
+// arguments of the call and reports invalid arguments as *Error.
+type BuiltinFunction func(runtime Runtime, arguments ...Object) Object
+
+type Builtin struct {
+	Name string
+	Fn   BuiltinFunction
+}
+
+func (builtin *Builtin) Type() ObjectType { return BUILTIN_OBJECT }
+func (builtin *Builtin) Inspect() string  { return "builtin " + builtin.Name }
+
+type Array struct {
+	Elements []Object
+}
+
+func (array *Array) Type() ObjectType { return ARRAY_OBJECT }
+func (array *Array) Inspect() string {
+	elements := []string{}
+	for _, element := range array.Elements {
+		elements = append(elements, element.Inspect())
+	}
+
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+type String struct {
+	Value string
+}
+
+func (str *String) Type() ObjectType { return STRING_OBJECT }
+func (str *String) Inspect() string  { return str.Value }
+
+// A HashKey identifies a value used as key of a hash. Equal values have equal
+// keys.
+type HashKey struct {
+	Type  ObjectType
+	Value uint64
+}
+
+// Hashable is implemented by all objects that can be used as keys of a hash.
+type Hashable interface {
+	Object
+	HashKey() HashKey
+}
+
+func (integer *Integer) HashKey() HashKey {
+	return HashKey{Type: integer.Type(), Value: uint64(integer.Value)}
+}
+
+func (boolean *Boolean) HashKey() HashKey {
+	var value uint64
+	if boolean.Value {
+		value = 1
+	}
+	return HashKey{Type: boolean.Type(), Value: value}
+}
+
+func (str *String) HashKey() HashKey {
+	hash := fnv.New64a()
+	hash.Write([]byte(str.Value))
+	return HashKey{Type: str.Type(), Value: hash.Sum64()}
+}
+
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+// A Hash maps keys to values and remembers the order in which the keys were
+// added.
+type Hash struct {
+	Pairs map[HashKey]HashPair
+	Keys  []HashKey // in insertion order
+}
+
+func NewHash() *Hash {
+	return &Hash{Pairs: map[HashKey]HashPair{}, Keys: []HashKey{}}
+}
+
+// Set adds a pair to the hash. Setting an existing key replaces its value but
+// keeps its position.
+func (hash *Hash) Set(key Hashable, value Object) {
+	hashKey := key.HashKey()
+	if _, ok := hash.Pairs[hashKey]; !ok {
+		hash.Keys = append(hash.Keys, hashKey)
+	}
+	hash.Pairs[hashKey] = HashPair{Key: key, Value: value}
+}
+
+func (hash *Hash) Get(key Hashable) (Object, bool) {
+	pair, ok := hash.Pairs[key.HashKey()]
+	return pair.Value, ok
+}
+
+func (hash *Hash) Type() ObjectType { return HASH_OBJECT }
+func (hash *Hash) Inspect() string {
+	pairs := []string{}
+	for _, key := range hash.Keys {
+		pair := hash.Pairs[key]
+		pairs = append(pairs, pair.Key.Inspect()+": "+pair.Value.Inspect())
+	}
+
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// A Module is the namespace of an imported script. It holds the values of
+// the bindings the script exports.
+type Module struct {
+	Path    string   // absolute path of the script, or the name of a builtin module
+	Names   []string // exported names in declaration order
+	Exports map[string]Object
+}
+
+func (module *Module) Type() ObjectType { return MODULE_OBJECT }
+func (module *Module) Inspect() string {
+	return "module " + filepath.Base(module.Path) + " {" + strings.Join(module.Names, ", ") + "}"
+}
+
+// A Task is a function running on a goroutine of its own. Its Result is set
+// before Done is closed.
+type Task struct {
+	Done   chan struct{}
+	Result Object
+}
+
+func NewTask() *Task {
+	return &Task{Done: make(chan struct{})}
+}
+
+func (task *Task) Type() ObjectType { return TASK_OBJECT }
+func (task *Task) Inspect() string {
+	select {
+	case <-task.Done:
+		return "task done"
+	default:
+		return "task running"
+	}
+}
+
+// A Channel passes values between tasks. Closing it closes Closed, Values
+// stays open, so senders which race with the close do not panic and the
+// buffered values can still be received.
+type Channel struct {
+	Values chan Object
+	Closed chan struct{}
+
+	closeOnce sync.Once
+}
+
+func NewChannel(capacity int) *Channel {
+	return &Channel{Values: make(chan Object, capacity), Closed: make(chan struct{})}
+}
+
+// Close closes the channel. It reports false if it was closed already.
+func (channel *Channel) Close() bool {
+	closed := false
+	channel.closeOnce.Do(func() {
+		close(channel.Closed)
+		closed = true
+	})
+	return closed
+}
+
+func (channel *Channel) Type() ObjectType { return CHANNEL_OBJECT }
+func (channel *Channel) Inspect() string {
+	return fmt.Sprintf("channel(%d)", cap(channel.Values))
+}
@@ -5,7 +5,9 @@ import (
 	"monkey/ast"
 	"monkey/lexer"
 	"monkey/token"
+	"slices"
 	"strconv"
+	"strings"
 )
 
 type operatorPrecedence int
@@ -13,15 +15,20 @@ type operatorPrecedence int
 const (
 	_ operatorPrecedence = iota
 	LOWEST
+	TERNARY     // a ? b : c
+	NULLISH     // ??
 	EQUALS      // ==
 	LESSGREATER // > or <
 	SUM         // +
 	PRODUCT     // *
 	PREFIX      // -X or !X
 	CALL        // myFunction(x)
+	INDEX       // array[index] or hash.key
 )
 
 var precedences = map[token.TokenType]operatorPrecedence{
+	token.QUESTION: TERNARY,
+	token.NULLISH:  NULLISH,
 	token.EQ:       EQUALS,
 	token.NOT_EQ:   EQUALS,
 	token.LT:       LESSGREATER,
@@ -31,6 +38,21 @@ var precedences = map[token.TokenType]operatorPrecedence{
 	token.ASTERISK: PRODUCT,
 	token.SLASH:    PRODUCT,
 	token.LPAREN:   CALL,
+	token.LBRACKET: INDEX,
+
+	token.QUESTION_BRACKET: INDEX,
+	token.DOT:              INDEX,
+	token.QUESTION_DOT:     INDEX,
+}
+
+// InfixPrecedence returns how tightly an infix operator binds its operands,
+// higher values bind tighter. Tokens that are no infix operators return
+// LOWEST.
+func InfixPrecedence(tokenType token.TokenType) int {
+	if priority, ok := precedences[tokenType]; ok {
+		return int(priority)
+	}
+	return int(LOWEST)
 }
 
 type Parser struct {
@@ -39,7 +61,16 @@ type Parser struct {
 	currentToken token.Token
 	nextToken    token.Token
 
-	errors []string
+	errors   []string
+	comments []*ast.Comment
+	depth    int // number of blocks around the current token
+	brackets int // number of brackets open at the current token
+
+	// colons holds the brackets of the conditionals and hash keys being
+	// parsed, which a colon at the same nesting belongs to
+	colons []int
+	// split is the [ of a ?[ token which starts a conditional
+	split *token.Token
 
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
@@ -54,14 +85,21 @@ func New(lex *lexer.Lexer) *Parser {
 	parser.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	parser.registerPrefix(token.IDENT, parser.parseIdentifier)
 	parser.registerPrefix(token.INT, parser.parseIntegerLiteral)
+	parser.registerPrefix(token.FLOAT, parser.parseFloatLiteral)
 	parser.registerPrefix(token.TRUE, parser.parseBoolean)
 	parser.registerPrefix(token.FALSE, parser.parseBoolean)
+	parser.registerPrefix(token.NULL, parser.parseNull)
+	parser.registerPrefix(token.STRING, parser.parseStringLiteral)
 	parser.registerPrefix(token.PLUS, parser.parsePrefixExpression)
 	parser.registerPrefix(token.MINUS, parser.parsePrefixExpression)
 	parser.registerPrefix(token.BANG, parser.parsePrefixExpression)
 	parser.registerPrefix(token.LPAREN, parser.parseGroupedExpression)
 	parser.registerPrefix(token.IF, parser.parseIfExpression)
 	parser.registerPrefix(token.FUNCTION, parser.parseFunctionLiteral)
+	parser.registerPrefix(token.LBRACKET, parser.parseArrayLiteral)
+	parser.registerPrefix(token.LBRACE, parser.parseHashLiteral)
+	parser.registerPrefix(token.MATCH, parser.parseMatchExpression)
+	parser.registerPrefix(token.TRY, parser.parseTryExpression)
 
 	parser.infixParseFns = make(map[token.TokenType]infixParseFn)
 	parser.registerInfix(token.EQ, parser.parseInfixExpression)
@@ -73,6 +111,12 @@ func New(lex *lexer.Lexer) *Parser {
 	parser.registerInfix(token.ASTERISK, parser.parseInfixExpression)
 	parser.registerInfix(token.SLASH, parser.parseInfixExpression)
 	parser.registerInfix(token.LPAREN, parser.parseCallExpression)
+	parser.registerInfix(token.NULLISH, parser.parseInfixExpression)
+	parser.registerInfix(token.QUESTION, parser.parseConditionalExpression)
+	parser.registerInfix(token.LBRACKET, parser.parseIndexExpression)
+	parser.registerInfix(token.QUESTION_BRACKET, parser.parseIndexExpression)
+	parser.registerInfix(token.DOT, parser.parseMemberExpression)
+	parser.registerInfix(token.QUESTION_DOT, parser.parseMemberExpression)
 
 	// Read two tokens, so currentToken and nextToken are set initially
 	parser.advanceTokens()
@@ -94,6 +138,8 @@ func (parser *Parser) ParseProgram() (errors []string, program *ast.Program) {
 		parser.advanceTokens()
 	}
 
+	program.Comments = parser.comments
+
 	if len(parser.errors) > 0 {
 		return parser.errors, program
 	}
@@ -113,8 +159,19 @@ func (parser *Parser) parseStatement() ast.Statement {
 	switch parser.currentToken.Type {
 	case token.LET:
 		return parser.parseLetStatement()
+	case token.IMPORT:
+		return parser.parseImportStatement()
+	case token.EXPORT:
+		return parser.parseExportStatement()
 	case token.RETURN:
 		return parser.parseReturnStatement()
+	case token.THROW:
+		return parser.parseThrowStatement()
+	case token.FUNCTION:
+		if parser.nextTokenIs(token.IDENT) {
+			return parser.parseFunctionStatement()
+		}
+		return parser.parseExpressionStatement()
 	case token.SEMICOLON:
 		return nil
 	default:
@@ -125,10 +182,13 @@ func (parser *Parser) parseStatement() ast.Statement {
 func (parser *Parser) parseLetStatement() *ast.LetStatement {
 	tok := parser.currentToken
 
-	parser.advanceToExpectedToken(token.IDENT)
-	identifier := &ast.Identifier{
-		Token: parser.currentToken,
-		Value: parser.currentToken.Literal,
+	name := parser.parseBindingName()
+	if name == nil {
+		// Keep parsing the value to report its errors as well
+		name = &ast.Identifier{
+			Token: parser.currentToken,
+			Value: parser.currentToken.Literal,
+		}
 	}
 
 	parser.advanceToExpectedToken(token.ASSIGN)
@@ -138,11 +198,68 @@ func (parser *Parser) parseLetStatement() *ast.LetStatement {
 
 	return &ast.LetStatement{
 		Token: tok,
-		Name:  identifier,
+		Name:  name,
 		Value: expression,
 	}
 }
 
+func (parser *Parser) parseImportStatement() ast.Statement {
+	statement := &ast.ImportStatement{Token: parser.currentToken}
+
+	if !parser.advanceToExpectedToken(token.STRING) {
+		return nil
+	}
+
+	path, ok := parser.parseStringLiteral().(*ast.StringLiteral)
+	if !ok {
+		return nil
+	}
+	statement.Path = path
+
+	// as is no keyword, so it can still be used as a name
+	if !parser.nextTokenIs(token.IDENT) || parser.nextToken.Literal != "as" {
+		parser.errors = append(parser.errors, fmt.Sprintf("expected next token to be as, got %s instead", parser.nextToken.Type))
+		return nil
+	}
+	parser.advanceTokens()
+
+	if !parser.advanceToExpectedToken(token.IDENT) {
+		return nil
+	}
+	statement.Alias = &ast.Identifier{
+		Token: parser.currentToken,
+		Value: parser.currentToken.Literal,
+	}
+
+	return statement
+}
+
+func (parser *Parser) parseExportStatement() ast.Statement {
+	statement := &ast.ExportStatement{Token: parser.currentToken}
+
+	if parser.depth > 0 {
+		parser.errors = append(parser.errors, "export is only allowed at the top level")
+	}
+
+	parser.advanceTokens()
+
+	switch {
+	case parser.currentTokenIs(token.LET):
+		statement.Statement = parser.parseLetStatement()
+	case parser.currentTokenIs(token.FUNCTION) && parser.nextTokenIs(token.IDENT):
+		declaration := parser.parseFunctionStatement()
+		if declaration == nil {
+			return nil
+		}
+		statement.Statement = declaration
+	default:
+		parser.errors = append(parser.errors, fmt.Sprintf("expected let or fn after export, got %s instead", parser.currentToken.Type))
+		return nil
+	}
+
+	return statement
+}
+
 func (parser *Parser) parseReturnStatement() *ast.ReturnStatement {
 	tok := parser.currentToken
 
@@ -155,6 +272,18 @@ func (parser *Parser) parseReturnStatement() *ast.ReturnStatement {
 	}
 }
 
+func (parser *Parser) parseThrowStatement() *ast.ThrowStatement {
+	tok := parser.currentToken
+
+	parser.advanceTokens()
+	expression := parser.parseExpression(LOWEST)
+
+	return &ast.ThrowStatement{
+		Token: tok,
+		Value: expression,
+	}
+}
+
 func (parser *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	tok := parser.currentToken
 
@@ -173,6 +302,7 @@ func (parser *Parser) parseBlockStatement() *ast.BlockStatement {
 	}
 
 	parser.advanceTokens()
+	parser.depth += 1
 
 	for !parser.currentTokenIs(token.EOF) && !parser.currentTokenIs(token.RBRACE) {
 		statement := parser.parseStatement()
@@ -184,6 +314,10 @@ func (parser *Parser) parseBlockStatement() *ast.BlockStatement {
 		parser.advanceTokens()
 	}
 
+	parser.depth -= 1
+
+	blockStatement.EndToken = parser.currentToken
+
 	return blockStatement
 }
 
@@ -192,12 +326,10 @@ func (parser *Parser) parseGroupedExpression() ast.Expression {
 
 	expression := parser.parseExpression(LOWEST)
 
-	if !parser.nextTokenIs(token.RPAREN) {
+	if !parser.advanceToExpectedToken(token.RPAREN) {
 		return nil
 	}
 
-	parser.advanceTokens()
-
 	return expression
 }
 
@@ -236,6 +368,51 @@ func (parser *Parser) parseIfExpression() ast.Expression {
 	return ifExpression
 }
 
+func (parser *Parser) parseTryExpression() ast.Expression {
+	tryExpression := &ast.TryExpression{
+		Token: parser.currentToken,
+	}
+
+	if !parser.advanceToExpectedToken(token.LBRACE) {
+		return nil
+	}
+
+	tryExpression.Block = parser.parseBlockStatement()
+
+	if parser.nextTokenIs(token.CATCH) {
+		parser.advanceTokens()
+
+		if !parser.advanceToExpectedToken(token.LPAREN) || !parser.advanceToExpectedToken(token.IDENT) {
+			return nil
+		}
+
+		tryExpression.Parameter = parser.parseIdentifier().(*ast.Identifier)
+
+		if !parser.advanceToExpectedToken(token.RPAREN) || !parser.advanceToExpectedToken(token.LBRACE) {
+			return nil
+		}
+
+		tryExpression.Catch = parser.parseBlockStatement()
+	}
+
+	if parser.nextTokenIs(token.FINALLY) {
+		parser.advanceTokens()
+
+		if !parser.advanceToExpectedToken(token.LBRACE) {
+			return nil
+		}
+
+		tryExpression.Finally = parser.parseBlockStatement()
+	}
+
+	if tryExpression.Catch == nil && tryExpression.Finally == nil {
+		parser.errors = append(parser.errors, fmt.Sprintf("expected catch or finally after try block, got %s instead", parser.nextToken.Type))
+		return nil
+	}
+
+	return tryExpression
+}
+
 func (parser *Parser) parseIdentifier() ast.Expression {
 	return &ast.Identifier{
 		Token: parser.currentToken,
@@ -246,6 +423,11 @@ func (parser *Parser) parseIdentifier() ast.Expression {
 func (parser *Parser) parseExpression(precedence operatorPrecedence) ast.Expression {
 	prefixFn := parser.prefixParseFns[parser.currentToken.Type]
 
+	if parser.currentTokenIs(token.ILLEGAL) && strings.HasPrefix(parser.currentToken.Literal, `"`) {
+		parser.errors = append(parser.errors, "unterminated string")
+		return nil
+	}
+
 	if prefixFn == nil {
 		msg := fmt.Sprintf("no prefix parse expression for %s found", parser.currentToken.Type)
 		parser.errors = append(parser.errors, msg)
@@ -295,9 +477,125 @@ func (parser *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	}
 }
 
+// parseConditionalExpression parses the part after the condition. The
+// alternative is parsed with the lowest precedence, so conditional
+// expressions are right associative.
+func (parser *Parser) parseConditionalExpression(condition ast.Expression) ast.Expression {
+	expression := &ast.ConditionalExpression{
+		Token:     parser.currentToken,
+		Condition: condition,
+	}
+
+	parser.colons = append(parser.colons, parser.brackets)
+	parser.advanceTokens()
+	expression.Consequence = parser.parseExpression(LOWEST)
+	parser.colons = parser.colons[:len(parser.colons)-1]
+
+	if !parser.advanceToExpectedToken(token.COLON) {
+		return nil
+	}
+
+	parser.advanceTokens()
+	expression.Alternative = parser.parseExpression(LOWEST)
+
+	return expression
+}
+
+func (parser *Parser) parseMatchExpression() ast.Expression {
+	expression := &ast.MatchExpression{
+		Token: parser.currentToken,
+		Arms:  []*ast.MatchArm{},
+	}
+
+	if !parser.advanceToExpectedToken(token.LPAREN) {
+		return nil
+	}
+
+	parser.advanceTokens()
+	expression.Subject = parser.parseExpression(LOWEST)
+
+	if !parser.advanceToExpectedToken(token.RPAREN) || !parser.advanceToExpectedToken(token.LBRACE) {
+		return nil
+	}
+
+	for !parser.nextTokenIs(token.RBRACE) {
+		parser.advanceTokens()
+
+		arm := parser.parseMatchArm()
+		if arm == nil {
+			return nil
+		}
+		expression.Arms = append(expression.Arms, arm)
+
+		if !parser.nextTokenIs(token.RBRACE) && !parser.advanceToExpectedToken(token.COMMA) {
+			return nil
+		}
+	}
+
+	parser.advanceTokens()
+	expression.EndToken = parser.currentToken
+
+	return expression
+}
+
+func (parser *Parser) parseMatchArm() *ast.MatchArm {
+	pattern := parser.parsePattern()
+	if pattern == nil || !parser.advanceToExpectedToken(token.ARROW) {
+		return nil
+	}
+
+	arm := &ast.MatchArm{
+		Token:   parser.currentToken,
+		Pattern: pattern,
+	}
+
+	parser.advanceTokens()
+
+	if parser.currentTokenIs(token.LBRACE) {
+		arm.Body = parser.parseBlockStatement()
+	} else if body := parser.parseExpression(LOWEST); body != nil {
+		arm.Body = body
+	} else {
+		return nil
+	}
+
+	return arm
+}
+
+func (parser *Parser) parseFunctionStatement() ast.Statement {
+	tok := parser.currentToken
+
+	parser.advanceTokens()
+	identifier := &ast.Identifier{
+		Token: parser.currentToken,
+		Value: parser.currentToken.Literal,
+	}
+
+	function := parser.parseFunction(tok)
+	if function == nil {
+		return nil
+	}
+
+	return &ast.FunctionStatement{
+		Token:    tok,
+		Name:     identifier,
+		Function: function,
+	}
+}
+
 func (parser *Parser) parseFunctionLiteral() ast.Expression {
+	function := parser.parseFunction(parser.currentToken)
+	if function == nil {
+		return nil
+	}
+	return function
+}
+
+// parseFunction parses the parameters and body of a function, tok is the
+// fn token which starts it.
+func (parser *Parser) parseFunction(tok token.Token) *ast.FunctionLiteral {
 	functionLiteral := &ast.FunctionLiteral{
-		Token: parser.currentToken,
+		Token: tok,
 	}
 
 	if !parser.advanceToExpectedToken(token.LPAREN) {
@@ -310,37 +608,97 @@ func (parser *Parser) parseFunctionLiteral() ast.Expression {
 	}
 	functionLiteral.Body = parser.parseBlockStatement()
 
+	if end := functionLiteral.Body.EndToken; end.Type == token.RBRACE {
+		afterEnd := token.Position{Line: end.Position.Line, Column: end.Position.Column + 1}
+		functionLiteral.Source = parser.lexer.Text(tok.Position, afterEnd)
+	}
+
 	return functionLiteral
 }
 
-func (parser *Parser) parseFunctionParameters() []*ast.Identifier {
-	parameters := []*ast.Identifier{}
+func (parser *Parser) parseFunctionParameters() []*ast.Parameter {
+	parameters := []*ast.Parameter{}
 
 	if parser.nextTokenIs(token.RPAREN) {
 		parser.advanceTokens()
 		return parameters
 	}
 
-	for parser.advanceToExpectedToken(token.IDENT) {
-		parameters = append(parameters, &ast.Identifier{
-			Token: parser.currentToken,
-			Value: parser.currentToken.Literal,
-		})
+	for {
+		parameter := parser.parseFunctionParameter()
+		if parameter == nil {
+			return nil
+		}
+
+		parameters = append(parameters, parameter)
 
-		if parser.nextTokenIs(token.COMMA) {
-			parser.advanceTokens()
-		} else {
+		if !parser.nextTokenIs(token.COMMA) {
 			break
 		}
+		parser.advanceTokens()
 	}
 
 	if !parser.advanceToExpectedToken(token.RPAREN) {
 		return nil
 	}
 
+	parser.checkParameterOrder(parameters)
+
 	return parameters
 }
 
+func (parser *Parser) parseFunctionParameter() *ast.Parameter {
+	if parser.nextTokenIs(token.ELLIPSIS) {
+		parser.advanceTokens()
+		parameter := &ast.Parameter{Token: parser.currentToken, Rest: true}
+
+		if !parser.advanceToExpectedToken(token.IDENT) {
+			return nil
+		}
+		parameter.Name = parser.parseIdentifier().(*ast.Identifier)
+
+		return parameter
+	}
+
+	tok := parser.nextToken
+
+	name := parser.parseBindingName()
+	if name == nil {
+		return nil
+	}
+
+	parameter := &ast.Parameter{
+		Token: tok,
+		Name:  name,
+	}
+
+	if parser.nextTokenIs(token.ASSIGN) {
+		parser.advanceTokens()
+		parser.advanceTokens()
+		parameter.Default = parser.parseExpression(LOWEST)
+	}
+
+	return parameter
+}
+
+// checkParameterOrder makes sure that optional parameters follow required
+// ones and a rest parameter comes last, so arguments can be assigned to
+// parameters by position.
+func (parser *Parser) checkParameterOrder(parameters []*ast.Parameter) {
+	hasDefault := false
+
+	for index, parameter := range parameters {
+		switch {
+		case parameter.Rest && index < len(parameters)-1:
+			parser.errors = append(parser.errors, fmt.Sprintf("rest parameter %s must be the last parameter", parameter))
+		case parameter.Default != nil:
+			hasDefault = true
+		case !parameter.Rest && hasDefault:
+			parser.errors = append(parser.errors, fmt.Sprintf("parameter %s without default value follows parameter with default value", parameter))
+		}
+	}
+}
+
 func (parser *Parser) parseCallExpression(left ast.Expression) ast.Expression {
 	callExpression := &ast.CallExpression{
 		Token:    parser.currentToken,
@@ -353,27 +711,110 @@ func (parser *Parser) parseCallExpression(left ast.Expression) ast.Expression {
 }
 
 func (parser *Parser) parseCallExpressionArguments() []ast.Expression {
-	arguments := []ast.Expression{}
+	return parser.parseExpressionList(token.RPAREN)
+}
 
-	if parser.nextTokenIs(token.RPAREN) {
+func (parser *Parser) parseArrayLiteral() ast.Expression {
+	arrayLiteral := &ast.ArrayLiteral{
+		Token: parser.currentToken,
+	}
+
+	arrayLiteral.Elements = parser.parseExpressionList(token.RBRACKET)
+
+	return arrayLiteral
+}
+
+func (parser *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	indexExpression := &ast.IndexExpression{
+		Token:    parser.currentToken,
+		Left:     left,
+		Optional: parser.currentTokenIs(token.QUESTION_BRACKET),
+	}
+
+	parser.advanceTokens()
+	indexExpression.Index = parser.parseExpression(LOWEST)
+
+	if !parser.advanceToExpectedToken(token.RBRACKET) {
+		return nil
+	}
+
+	return indexExpression
+}
+
+// parseMemberExpression parses the property after a . or ?. token.
+func (parser *Parser) parseMemberExpression(object ast.Expression) ast.Expression {
+	memberExpression := &ast.MemberExpression{
+		Token:    parser.currentToken,
+		Object:   object,
+		Optional: parser.currentTokenIs(token.QUESTION_DOT),
+	}
+
+	if !parser.advanceToExpectedToken(token.IDENT) {
+		return nil
+	}
+
+	memberExpression.Property = &ast.Identifier{
+		Token: parser.currentToken,
+		Value: parser.currentToken.Literal,
+	}
+
+	return memberExpression
+}
+
+func (parser *Parser) parseHashLiteral() ast.Expression {
+	hashLiteral := &ast.HashLiteral{
+		Token: parser.currentToken,
+		Pairs: []ast.HashPair{},
+	}
+
+	for !parser.nextTokenIs(token.RBRACE) {
+		parser.colons = append(parser.colons, parser.brackets)
 		parser.advanceTokens()
-		return arguments
+		key := parser.parseExpression(LOWEST)
+		parser.colons = parser.colons[:len(parser.colons)-1]
+
+		if !parser.advanceToExpectedToken(token.COLON) {
+			return nil
+		}
+
+		parser.advanceTokens()
+		value := parser.parseExpression(LOWEST)
+
+		hashLiteral.Pairs = append(hashLiteral.Pairs, ast.HashPair{Key: key, Value: value})
+
+		if !parser.nextTokenIs(token.RBRACE) && !parser.advanceToExpectedToken(token.COMMA) {
+			return nil
+		}
+	}
+
+	parser.advanceTokens()
+
+	return hashLiteral
+}
+
+// parseExpressionList parses comma separated expressions up to the end token.
+func (parser *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
+	expressions := []ast.Expression{}
+
+	if parser.nextTokenIs(end) {
+		parser.advanceTokens()
+		return expressions
 	}
 
 	parser.advanceTokens()
-	arguments = append(arguments, parser.parseExpression(LOWEST))
+	expressions = append(expressions, parser.parseExpression(LOWEST))
 
 	for parser.nextTokenIs(token.COMMA) {
 		parser.advanceTokens()
 		parser.advanceTokens()
-		arguments = append(arguments, parser.parseExpression(LOWEST))
+		expressions = append(expressions, parser.parseExpression(LOWEST))
 	}
 
-	if !parser.advanceToExpectedToken(token.RPAREN) {
+	if !parser.advanceToExpectedToken(end) {
 		return nil
 	}
 
-	return arguments
+	return expressions
 }
 
 func (parser *Parser) parseIntegerLiteral() ast.Expression {
@@ -391,6 +832,74 @@ func (parser *Parser) parseIntegerLiteral() ast.Expression {
 	}
 }
 
+func (parser *Parser) parseFloatLiteral() ast.Expression {
+	value, err := strconv.ParseFloat(parser.currentToken.Literal, 64)
+
+	if err != nil {
+		msg := fmt.Sprintf("could not parse %q as float", parser.currentToken.Literal)
+		parser.errors = append(parser.errors, msg)
+		return nil
+	}
+
+	return &ast.FloatLiteral{
+		Token: parser.currentToken,
+		Value: value,
+	}
+}
+
+func (parser *Parser) parseNull() ast.Expression {
+	return &ast.NullLiteral{Token: parser.currentToken}
+}
+
+func (parser *Parser) parseStringLiteral() ast.Expression {
+	value, err := unescape(parser.currentToken.Literal)
+
+	if err != nil {
+		parser.errors = append(parser.errors, err.Error())
+		return nil
+	}
+
+	return &ast.StringLiteral{
+		Token: parser.currentToken,
+		Value: value,
+	}
+}
+
+var escapeSequences = map[byte]byte{
+	'\\': '\\',
+	'"':  '"',
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+}
+
+// unescape replaces the escape sequences in the text of a string literal.
+func unescape(literal string) (string, error) {
+	var out strings.Builder
+
+	for index := 0; index < len(literal); index++ {
+		char := literal[index]
+
+		if char != '\\' {
+			out.WriteByte(char)
+			continue
+		}
+
+		index++
+		if index == len(literal) {
+			return "", fmt.Errorf("unterminated escape sequence in %q", literal)
+		}
+
+		replacement, ok := escapeSequences[literal[index]]
+		if !ok {
+			return "", fmt.Errorf("unknown escape sequence \\%c", literal[index])
+		}
+		out.WriteByte(replacement)
+	}
+
+	return out.String(), nil
+}
+
 func (parser *Parser) parseBoolean() ast.Expression {
 	value, err := strconv.ParseBool(parser.currentToken.Literal)
 
@@ -408,7 +917,71 @@ func (parser *Parser) parseBoolean() ast.Expression {
 
 func (parser *Parser) advanceTokens() {
 	parser.currentToken = parser.nextToken
-	parser.nextToken = parser.lexer.GetNextToken()
+	switch parser.currentToken.Type {
+	case token.LPAREN, token.LBRACKET, token.QUESTION_BRACKET, token.LBRACE:
+		parser.brackets++
+	case token.RPAREN, token.RBRACKET, token.RBRACE:
+		parser.brackets--
+	}
+
+	parser.nextToken = parser.readToken()
+
+	// The lexer reads "a ?[b] : c" as optional index, but it is a
+	// conditional whose consequence is an array
+	if parser.nextTokenIs(token.QUESTION_BRACKET) && parser.startsConditional() {
+		position := parser.nextToken.Position
+		parser.nextToken = token.Token{Type: token.QUESTION, Literal: "?", Position: position}
+
+		position.Column++
+		parser.split = &token.Token{Type: token.LBRACKET, Literal: "[", Position: position}
+	}
+}
+
+// startsConditional reports whether the next ?[ token is followed by a colon
+// after its closing bracket, which does not belong to a conditional or hash
+// key around it.
+func (parser *Parser) startsConditional() bool {
+	if slices.Contains(parser.colons, parser.brackets) {
+		return false
+	}
+
+	lookahead := parser.lexer.Copy()
+	for depth := 1; depth > 0; {
+		switch lookahead.GetNextToken().Type {
+		case token.LPAREN, token.LBRACKET, token.QUESTION_BRACKET, token.LBRACE:
+			depth++
+		case token.RPAREN, token.RBRACKET, token.RBRACE:
+			depth--
+		case token.EOF:
+			return false
+		}
+	}
+
+	for {
+		tok := lookahead.GetNextToken()
+		if tok.Type != token.COMMENT {
+			return tok.Type == token.COLON
+		}
+	}
+}
+
+// readToken returns the next token of the lexer, collecting all comments
+// on the way.
+func (parser *Parser) readToken() token.Token {
+	if split := parser.split; split != nil {
+		parser.split = nil
+		return *split
+	}
+
+	for {
+		tok := parser.lexer.GetNextToken()
+
+		if tok.Type != token.COMMENT {
+			return tok
+		}
+
+		parser.comments = append(parser.comments, &ast.Comment{Token: tok})
+	}
 }
 
 func (parser *Parser) advanceToExpectedToken(tokenType token.TokenType) bool {
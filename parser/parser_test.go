@@ -36,33 +36,38 @@ func TestParseProgram(t *testing.T) {
 		Statements: []ast.Statement{
 			&ast.LetStatement{
 				Token: token.Token{
-					Type:    token.LET,
-					Literal: "let",
+					Type:     token.LET,
+					Literal:  "let",
+					Position: token.Position{Line: 2, Column: 2},
 				},
 				Name: &ast.Identifier{
 					Token: token.Token{
-						Type:    token.IDENT,
-						Literal: "x",
+						Type:     token.IDENT,
+						Literal:  "x",
+						Position: token.Position{Line: 2, Column: 6},
 					},
 					Value: "x",
 				},
 				Value: &ast.IntegerLiteral{
 					Token: token.Token{
-						Type:    token.INT,
-						Literal: "5",
+						Type:     token.INT,
+						Literal:  "5",
+						Position: token.Position{Line: 2, Column: 10},
 					},
 					Value: 5,
 				},
 			},
 			&ast.ReturnStatement{
 				Token: token.Token{
-					Type:    token.RETURN,
-					Literal: "return",
+					Type:     token.RETURN,
+					Literal:  "return",
+					Position: token.Position{Line: 4, Column: 2},
 				},
 				Value: &ast.IntegerLiteral{
 					Token: token.Token{
-						Type:    token.INT,
-						Literal: "5",
+						Type:     token.INT,
+						Literal:  "5",
+						Position: token.Position{Line: 4, Column: 9},
 					},
 					Value: 5,
 				},
@@ -103,13 +108,15 @@ func TestIdentifierExpression(t *testing.T) {
 		Statements: []ast.Statement{
 			&ast.ExpressionStatement{
 				Token: token.Token{
-					Type:    token.IDENT,
-					Literal: "foobar",
+					Type:     token.IDENT,
+					Literal:  "foobar",
+					Position: token.Position{Line: 1, Column: 1},
 				},
 				Value: &ast.Identifier{
 					Token: token.Token{
-						Type:    token.IDENT,
-						Literal: "foobar",
+						Type:     token.IDENT,
+						Literal:  "foobar",
+						Position: token.Position{Line: 1, Column: 1},
 					},
 					Value: "foobar",
 				},
@@ -131,13 +138,15 @@ func TestIntegerLiteralExpression(t *testing.T) {
 		Statements: []ast.Statement{
 			&ast.ExpressionStatement{
 				Token: token.Token{
-					Type:    token.INT,
-					Literal: "5",
+					Type:     token.INT,
+					Literal:  "5",
+					Position: token.Position{Line: 1, Column: 1},
 				},
 				Value: &ast.IntegerLiteral{
 					Token: token.Token{
-						Type:    token.INT,
-						Literal: "5",
+						Type:     token.INT,
+						Literal:  "5",
+						Position: token.Position{Line: 1, Column: 1},
 					},
 					Value: 5,
 				},
@@ -169,19 +178,22 @@ func TestPrefixExpression(t *testing.T) {
 			Statements: []ast.Statement{
 				&ast.ExpressionStatement{
 					Token: token.Token{
-						Type:    testCase.prefixToken,
-						Literal: testCase.operator,
+						Type:     testCase.prefixToken,
+						Literal:  testCase.operator,
+						Position: token.Position{Line: 1, Column: 1},
 					},
 					Value: &ast.PrefixExpression{
 						Token: token.Token{
-							Type:    testCase.prefixToken,
-							Literal: testCase.operator,
+							Type:     testCase.prefixToken,
+							Literal:  testCase.operator,
+							Position: token.Position{Line: 1, Column: 1},
 						},
 						Operator: testCase.operator,
 						Right: &ast.IntegerLiteral{
 							Token: token.Token{
-								Type:    token.INT,
-								Literal: fmt.Sprintf("%d", testCase.integerValue),
+								Type:     token.INT,
+								Literal:  fmt.Sprintf("%d", testCase.integerValue),
+								Position: token.Position{Line: 1, Column: 2},
 							},
 							Value: testCase.integerValue,
 						},
@@ -221,26 +233,30 @@ func TestInfixExpression(t *testing.T) {
 			Statements: []ast.Statement{
 				&ast.ExpressionStatement{
 					Token: token.Token{
-						Type:    token.INT,
-						Literal: fmt.Sprintf("%d", testCase.leftValue),
+						Type:     token.INT,
+						Literal:  fmt.Sprintf("%d", testCase.leftValue),
+						Position: token.Position{Line: 1, Column: 1},
 					},
 					Value: &ast.InfixExpression{
 						Token: token.Token{
-							Type:    testCase.infixToken,
-							Literal: testCase.operator,
+							Type:     testCase.infixToken,
+							Literal:  testCase.operator,
+							Position: token.Position{Line: 1, Column: 3},
 						},
 						Operator: testCase.operator,
 						Left: &ast.IntegerLiteral{
 							Token: token.Token{
-								Type:    token.INT,
-								Literal: fmt.Sprintf("%d", testCase.leftValue),
+								Type:     token.INT,
+								Literal:  fmt.Sprintf("%d", testCase.leftValue),
+								Position: token.Position{Line: 1, Column: 1},
 							},
 							Value: testCase.leftValue,
 						},
 						Right: &ast.IntegerLiteral{
 							Token: token.Token{
-								Type:    token.INT,
-								Literal: fmt.Sprintf("%d", testCase.rightValue),
+								Type:     token.INT,
+								Literal:  fmt.Sprintf("%d", testCase.rightValue),
+								Position: token.Position{Line: 1, Column: 4 + len(testCase.operator)},
 							},
 							Value: testCase.rightValue,
 						},
@@ -319,9 +335,313 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"-(3 + 2)",
 			"(-(3 + 2))",
 		},
+		{
+			"a * [1, 2, 3, 4][b * c] * d",
+			"((a * ([1, 2, 3, 4][(b * c)])) * d)",
+		},
+		{
+			"add(a * b[2], b[1], 2 * [1, 2][1])",
+			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
+		},
+		{
+			"-a[0]",
+			"(-(a[0]))",
+		},
+		{
+			"f(x)[0](y)",
+			"(f(x)[0])(y)",
+		},
+	})
+}
+
+func TestArrayLiteral(t *testing.T) {
+	runStringTestCases(t, []stringTestCase{
+		{
+			"[]",
+			"[]",
+		},
+		{
+			"[1, 2 * 2, fn(x) { x }]",
+			"[1, (2 * 2), fn(x) { x }]",
+		},
+	})
+}
+
+func TestFloatLiteral(t *testing.T) {
+	runStringTestCases(t, []stringTestCase{
+		{"1.5", "1.5"},
+		{"-0.25 * 2", "((-0.25) * 2)"},
+		{"[1.0][0]", "([1.0][0])"},
+	})
+
+	_, program := New(lexer.New("2.50")).ParseProgram()
+	literal := program.Statements[0].(*ast.ExpressionStatement).Value.(*ast.FloatLiteral)
+
+	assert.Equal(t, 2.5, literal.Value)
+}
+
+func TestStringAndNullLiterals(t *testing.T) {
+	runStringTestCases(t, []stringTestCase{
+		{
+			`"hello world"`,
+			`"hello world"`,
+		},
+		{
+			`"tab\tquote\"backslash\\"`,
+			`"tab\tquote\"backslash\\"`,
+		},
+		{
+			`null`,
+			`null`,
+		},
 	})
 }
 
+func TestHashLiteral(t *testing.T) {
+	runStringTestCases(t, []stringTestCase{
+		{
+			"{}",
+			"{}",
+		},
+		{
+			`{"one": 1, two: 1 + 1, 3: [3],}`,
+			`{"one": 1, two: (1 + 1), 3: [3]}`,
+		},
+		{
+			"{a?[0]: b ?[1] : [2]}",
+			"{(a?[0]): (b ? [1] : [2])}",
+		},
+	})
+}
+
+func TestMemberAndNullishExpressions(t *testing.T) {
+	runStringTestCases(t, []stringTestCase{
+		{
+			"a.b.c",
+			"((a.b).c)",
+		},
+		{
+			"a?.b?[0]?.c",
+			"(((a?.b)?[0])?.c)",
+		},
+		{
+			"a.b(1).c",
+			"((a.b)(1).c)",
+		},
+		{
+			"-a.b",
+			"(-(a.b))",
+		},
+		{
+			"a ?? b ?? c",
+			"((a ?? b) ?? c)",
+		},
+		{
+			"a ?? b == c",
+			"(a ?? (b == c))",
+		},
+		{
+			"a?.b ?? -1",
+			"((a?.b) ?? (-1))",
+		},
+	})
+}
+
+func TestConditionalExpression(t *testing.T) {
+	runStringTestCases(t, []stringTestCase{
+		{
+			"a ? b : c",
+			"(a ? b : c)",
+		},
+		{
+			"a ? b : c ? d : e",
+			"(a ? b : (c ? d : e))",
+		},
+		{
+			"a ? b ? c : d : e",
+			"(a ? (b ? c : d) : e)",
+		},
+		{
+			"a < b ? a + 1 : b ?? 0",
+			"((a < b) ? (a + 1) : (b ?? 0))",
+		},
+		{
+			"(a ? b : c) + 1",
+			"((a ? b : c) + 1)",
+		},
+		{
+			"a ? [1] : []",
+			"(a ? [1] : [])",
+		},
+		{
+			"true ?[1] : [2]",
+			"(true ? [1] : [2])",
+		},
+		{
+			"true ? [1] : [2]",
+			"(true ? [1] : [2])",
+		},
+		{
+			"a + b ?[1, c?[0]] : [] // comment",
+			"((a + b) ? [1, (c?[0])] : [])",
+		},
+		{
+			"f(a ?[1] : [2])",
+			"f((a ? [1] : [2]))",
+		},
+		{
+			"a ? b?[1] : c?[2]",
+			"(a ? (b?[1]) : (c?[2]))",
+		},
+		{
+			"a ? (b ?[1] : [2]) : c",
+			"(a ? (b ? [1] : [2]) : c)",
+		},
+	})
+}
+
+func TestMatchExpression(t *testing.T) {
+	runStringTestCases(t, []stringTestCase{
+		{
+			"match (x) {}",
+			"match (x) {  }",
+		},
+		{
+			`match (x) { 1 => "one", -1 => "minus one", "a" => true, null => null, true => 1, _ => 0, }`,
+			`match (x) { 1 => "one", (-1) => "minus one", "a" => true, null => null, true => 1, _ => 0 }`,
+		},
+		{
+			"match (x) { 1.5 => 1, -2.5 => 2, [0.0] => 3 }",
+			"match (x) { 1.5 => 1, (-2.5) => 2, [0.0] => 3 }",
+		},
+		{
+			"match (x) { [] => 0, [a, _] => a, [a, ...rest] => rest }",
+			"match (x) { [] => 0, [a, _] => a, [a, ...rest] => rest }",
+		},
+		{
+			`match (x) { {"a": [b], c, 1: _} => b }`,
+			`match (x) { {"a": [b], c, 1: _} => b }`,
+		},
+		{
+			"match (x) { y => { y; } }",
+			"match (x) { y => { y } }",
+		},
+		{
+			"match (a + 1) { 2 => a } + 1",
+			"(match ((a + 1)) { 2 => a } + 1)",
+		},
+	})
+}
+
+func TestMatchPatternTypes(t *testing.T) {
+	input := `match (x) { _ => 1, y => 2, 3 => 3, [a, ...b] => 4, {c} => 5 }`
+
+	errors, program := New(lexer.New(input)).ParseProgram()
+	assert.Nil(t, errors)
+
+	match := program.Statements[0].(*ast.ExpressionStatement).Value.(*ast.MatchExpression)
+	assert.IsType(t, &ast.WildcardPattern{}, match.Arms[0].Pattern)
+	assert.IsType(t, &ast.Identifier{}, match.Arms[1].Pattern)
+	assert.IsType(t, &ast.LiteralPattern{}, match.Arms[2].Pattern)
+	assert.IsType(t, &ast.ArrayPattern{}, match.Arms[3].Pattern)
+	assert.IsType(t, &ast.HashPattern{}, match.Arms[4].Pattern)
+
+	hash := match.Arms[4].Pattern.(*ast.HashPattern)
+	assert.True(t, hash.Pairs[0].Shorthand)
+	assert.Equal(t, "c", hash.Pairs[0].Key.(*ast.StringLiteral).Value)
+}
+
+func TestMatchParserErrors(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"a ? b",
+			"expected next token to be :, got EOF instead",
+		},
+		{
+			"match x { _ => 1 }",
+			"expected next token to be (, got IDENT instead",
+		},
+		{
+			"match (x) { 1 2 }",
+			"expected next token to be =>, got INT instead",
+		},
+		{
+			"match (x) { -a => 1 }",
+			"expected next token to be INT, got IDENT instead",
+		},
+		{
+			"match (x) { fn => 1 }",
+			"expected pattern, got FUNCTION instead",
+		},
+		{
+			"match (x) { [...a, b] => 1 }",
+			"expected next token to be ], got , instead",
+		},
+		{
+			"match (x) { {[a]: b} => 1 }",
+			"expected hash pattern key, got [ instead",
+		},
+		{
+			"let [a, = b;",
+			"expected pattern, got = instead",
+		},
+		{
+			"fn([a b]) { a }",
+			"expected next token to be ,, got IDENT instead",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			// Only the first error is checked, the parser reports follow-up
+			// errors while it recovers
+			actual, _ := New(lexer.New(testCase.input)).ParseProgram()
+
+			if assert.NotEmpty(t, actual) {
+				assert.Equal(t, testCase.expected, actual[0])
+			}
+		})
+	}
+}
+
+func TestLiteralParserErrors(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			`"a\qb"`,
+			[]string{`unknown escape sequence \q`},
+		},
+		{
+			`let a = "open`,
+			[]string{"unterminated string"},
+		},
+		{
+			`{"a" 1}`,
+			[]string{
+				"expected next token to be :, got INT instead",
+				"no prefix parse expression for } found",
+			},
+		},
+		{
+			"a.1",
+			[]string{"expected next token to be IDENT, got INT instead"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			actual, _ := New(lexer.New(testCase.input)).ParseProgram()
+
+			assert.Equal(t, testCase.expected, actual)
+		})
+	}
+}
+
 func TestIfExpressions(t *testing.T) {
 	runStringTestCases(t, []stringTestCase{
 		{
@@ -335,6 +655,69 @@ func TestIfExpressions(t *testing.T) {
 	})
 }
 
+func TestTryExpressionAndThrowStatement(t *testing.T) {
+	runStringTestCases(t, []stringTestCase{
+		{
+			"try { f() } catch (e) { e.message }",
+			"try { f() } catch (e) { (e.message) }",
+		},
+		{
+			"try { f() } finally { g() }",
+			"try { f() } finally { g() }",
+		},
+		{
+			"let a = try { f() } catch (e) { 1 } finally { g() };",
+			"let a = try { f() } catch (e) { 1 } finally { g() };",
+		},
+		{
+			`throw "failed";`,
+			`throw "failed";`,
+		},
+		{
+			"throw e",
+			"throw e;",
+		},
+	})
+}
+
+func TestTryExpressionParserErrors(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"try { f() }",
+			"expected catch or finally after try block, got EOF instead",
+		},
+		{
+			"try f()",
+			"expected next token to be {, got IDENT instead",
+		},
+		{
+			"try { f() } catch { g() }",
+			"expected next token to be (, got { instead",
+		},
+		{
+			"try { f() } catch ([e]) { g() }",
+			"expected next token to be IDENT, got [ instead",
+		},
+		{
+			"try { f() } finally g()",
+			"expected next token to be {, got IDENT instead",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			actual, _ := New(lexer.New(testCase.input)).ParseProgram()
+
+			if assert.NotEmpty(t, actual) {
+				assert.Equal(t, testCase.expected, actual[0])
+			}
+		})
+	}
+}
+
 func TestFunctionLiteral(t *testing.T) {
 	runStringTestCases(t, []stringTestCase{
 		{
@@ -349,9 +732,171 @@ func TestFunctionLiteral(t *testing.T) {
 			"fn(a, b, c) { return a + b + c; }",
 			"fn(a, b, c) { return ((a + b) + c); }",
 		},
+		{
+			"fn(a, b = a + 1, ...rest) { rest }",
+			"fn(a, b = (a + 1), ...rest) { rest }",
+		},
+		{
+			"fn(...rest) { rest }",
+			"fn(...rest) { rest }",
+		},
+		{
+			`fn(_, [a, _], {b, "c": [c]} = {}) { a }`,
+			`fn(_, [a, _], {b, "c": [c]} = {}) { a }`,
+		},
+	})
+}
+
+func TestDestructuringLetStatement(t *testing.T) {
+	runStringTestCases(t, []stringTestCase{
+		{
+			"let [a, b, ...rest] = arr;",
+			"let [a, b, ...rest] = arr;",
+		},
+		{
+			`let {name, "age": [age, _]} = person;`,
+			`let {name, "age": [age, _]} = person;`,
+		},
+		{
+			"let [] = [];",
+			"let [] = [];",
+		},
+	})
+}
+
+func TestImportAndExport(t *testing.T) {
+	runStringTestCases(t, []stringTestCase{
+		{
+			`import "lib/math.mk" as math`,
+			`import "lib/math.mk" as math;`,
+		},
+		{
+			`import "./a.mk" as as; as`,
+			`import "./a.mk" as as;as`,
+		},
+		{
+			"export let [a, b] = c;",
+			"export let [a, b] = c;",
+		},
+		{
+			"export fn f(a) { a }",
+			"export fn f(a) { a }",
+		},
+	})
+}
+
+func TestImportAndExportErrors(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"import lib as lib;",
+			"expected next token to be STRING, got IDENT instead",
+		},
+		{
+			`import "lib" lib;`,
+			"expected next token to be as, got IDENT instead",
+		},
+		{
+			`import "lib" as 1;`,
+			"expected next token to be IDENT, got INT instead",
+		},
+		{
+			"export 1;",
+			"expected let or fn after export, got INT instead",
+		},
+		{
+			"export fn() {};",
+			"expected let or fn after export, got FUNCTION instead",
+		},
+		{
+			"fn f() { export let a = 1; }",
+			"export is only allowed at the top level",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			actual, _ := New(lexer.New(testCase.input)).ParseProgram()
+
+			if assert.NotEmpty(t, actual) {
+				assert.Equal(t, testCase.expected, actual[0])
+			}
+		})
+	}
+}
+
+func TestDestructuringNames(t *testing.T) {
+	input := "let _ = 1; let [_] = a; fn f([b], c) { b }"
+
+	errors, program := New(lexer.New(input)).ParseProgram()
+	assert.Nil(t, errors)
+
+	// A plain _ is an ordinary name, only inside patterns it is a wildcard
+	assert.IsType(t, &ast.Identifier{}, program.Statements[0].(*ast.LetStatement).Name)
+	array := program.Statements[1].(*ast.LetStatement).Name.(*ast.ArrayPattern)
+	assert.IsType(t, &ast.WildcardPattern{}, array.Elements[0])
+
+	parameter := program.Statements[2].(*ast.FunctionStatement).Function.Parameters[0]
+	assert.Equal(t, token.LBRACKET, parameter.Token.Type)
+	assert.Equal(t, token.Position{Line: 1, Column: 30}, parameter.Token.Position)
+}
+
+func TestFunctionStatement(t *testing.T) {
+	runStringTestCases(t, []stringTestCase{
+		{
+			"fn add(a, b = 1) { a + b }",
+			"fn add(a, b = 1) { (a + b) }",
+		},
+		{
+			"fn f() {}; f()",
+			"fn f() {  }f()",
+		},
+		{
+			"fn() { 1 }()",
+			"fn() { 1 }()",
+		},
 	})
 }
 
+func TestFunctionSource(t *testing.T) {
+	input := "let add = fn(a, b) {\n\ta + b\n};\nfn sub(a, b) { a - b }"
+
+	errors, program := New(lexer.New(input)).ParseProgram()
+	assert.Nil(t, errors)
+
+	literal := program.Statements[0].(*ast.LetStatement).Value.(*ast.FunctionLiteral)
+	declaration := program.Statements[1].(*ast.FunctionStatement)
+
+	assert.Equal(t, "fn(a, b) {\n\ta + b\n}", literal.Source)
+	assert.Equal(t, "fn sub(a, b) { a - b }", declaration.Function.Source)
+}
+
+func TestFunctionParameterErrors(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			"fn(...rest, a) { a }",
+			[]string{"rest parameter ...rest must be the last parameter"},
+		},
+		{
+			"fn(a = 1, b) { a }",
+			[]string{"parameter b without default value follows parameter with default value"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			actual, _ := New(lexer.New(testCase.input)).ParseProgram()
+
+			assert.Equal(t, testCase.expected, actual)
+		})
+	}
+}
+
 func TestFunctionLiteralParserErrors(t *testing.T) {
 	input := `fn(a b) return a;`
 
@@ -383,3 +928,23 @@ func TestCallExpression(t *testing.T) {
 		},
 	})
 }
+
+func TestComments(t *testing.T) {
+	input := `// leading
+let x = 5; // trailing
+if (x) {
+	// inside
+}`
+
+	parser := New(lexer.New(input))
+	errors, program := parser.ParseProgram()
+
+	comments := []string{}
+	for _, comment := range program.Comments {
+		comments = append(comments, comment.Token.Position.String()+" "+comment.String())
+	}
+
+	assert.Nil(t, errors)
+	assert.Equal(t, "let x = 5;if x {  }", program.String())
+	assert.Equal(t, []string{"1:1 // leading", "2:12 // trailing", "4:2 // inside"}, comments)
+}
@@ -0,0 +1,183 @@
+package parser
+
+import (
+	"fmt"
+	"monkey/ast"
+	"monkey/token"
+)
+
+// parseBindingName parses the name of a let statement or a parameter, which
+// starts at the next token. It is an identifier, or an array or hash pattern
+// to destructure the value. A plain _ is an ordinary name here.
+func (parser *Parser) parseBindingName() ast.Pattern {
+	if parser.nextTokenIs(token.LBRACKET) || parser.nextTokenIs(token.LBRACE) {
+		parser.advanceTokens()
+		return parser.parsePattern()
+	}
+
+	if !parser.advanceToExpectedToken(token.IDENT) {
+		return nil
+	}
+
+	return &ast.Identifier{
+		Token: parser.currentToken,
+		Value: parser.currentToken.Literal,
+	}
+}
+
+// parsePattern parses the pattern starting at the current token.
+func (parser *Parser) parsePattern() ast.Pattern {
+	switch parser.currentToken.Type {
+	case token.IDENT:
+		if parser.currentToken.Literal == "_" {
+			return &ast.WildcardPattern{Token: parser.currentToken}
+		}
+		return &ast.Identifier{
+			Token: parser.currentToken,
+			Value: parser.currentToken.Literal,
+		}
+
+	case token.INT, token.FLOAT, token.STRING, token.TRUE, token.FALSE, token.NULL, token.MINUS:
+		return parser.parseLiteralPattern()
+
+	case token.LBRACKET:
+		return parser.parseArrayPattern()
+
+	case token.LBRACE:
+		return parser.parseHashPattern()
+	}
+
+	parser.patternError("pattern")
+	return nil
+}
+
+func (parser *Parser) patternError(expected string) {
+	message := fmt.Sprintf("expected %s, got %s instead", expected, parser.currentToken.Type)
+	parser.errors = append(parser.errors, message)
+}
+
+func (parser *Parser) parseLiteralPattern() ast.Pattern {
+	tok := parser.currentToken
+
+	if tok.Type == token.MINUS {
+		var right ast.Expression
+		switch {
+		case parser.nextTokenIs(token.FLOAT):
+			parser.advanceTokens()
+			right = parser.parseFloatLiteral()
+		case parser.advanceToExpectedToken(token.INT):
+			right = parser.parseIntegerLiteral()
+		}
+		if right == nil {
+			return nil
+		}
+
+		return &ast.LiteralPattern{
+			Token: tok,
+			Value: &ast.PrefixExpression{Token: tok, Operator: "-", Right: right},
+		}
+	}
+
+	value := parser.prefixParseFns[tok.Type]()
+	if value == nil {
+		return nil
+	}
+
+	return &ast.LiteralPattern{Token: tok, Value: value}
+}
+
+func (parser *Parser) parseArrayPattern() ast.Pattern {
+	pattern := &ast.ArrayPattern{
+		Token:    parser.currentToken,
+		Elements: []ast.Pattern{},
+	}
+
+	for !parser.nextTokenIs(token.RBRACKET) {
+		parser.advanceTokens()
+
+		if parser.currentTokenIs(token.ELLIPSIS) {
+			// The rest pattern must be the last element
+			if !parser.advanceToExpectedToken(token.IDENT) {
+				return nil
+			}
+			pattern.Rest = parser.parsePattern()
+
+			if !parser.advanceToExpectedToken(token.RBRACKET) {
+				return nil
+			}
+			return pattern
+		}
+
+		element := parser.parsePattern()
+		if element == nil {
+			return nil
+		}
+		pattern.Elements = append(pattern.Elements, element)
+
+		if !parser.nextTokenIs(token.RBRACKET) && !parser.advanceToExpectedToken(token.COMMA) {
+			return nil
+		}
+	}
+
+	parser.advanceTokens()
+
+	return pattern
+}
+
+func (parser *Parser) parseHashPattern() ast.Pattern {
+	pattern := &ast.HashPattern{
+		Token: parser.currentToken,
+		Pairs: []ast.HashPatternPair{},
+	}
+
+	for !parser.nextTokenIs(token.RBRACE) {
+		parser.advanceTokens()
+
+		pair, ok := parser.parseHashPatternPair()
+		if !ok {
+			return nil
+		}
+		pattern.Pairs = append(pattern.Pairs, pair)
+
+		if !parser.nextTokenIs(token.RBRACE) && !parser.advanceToExpectedToken(token.COMMA) {
+			return nil
+		}
+	}
+
+	parser.advanceTokens()
+
+	return pattern
+}
+
+func (parser *Parser) parseHashPatternPair() (ast.HashPatternPair, bool) {
+	tok := parser.currentToken
+
+	switch tok.Type {
+	case token.IDENT:
+		// {name} is short for {"name": name}
+		key := &ast.StringLiteral{
+			Token: token.Token{Type: token.STRING, Literal: tok.Literal, Position: tok.Position},
+			Value: tok.Literal,
+		}
+		value := &ast.Identifier{Token: tok, Value: tok.Literal}
+
+		return ast.HashPatternPair{Key: key, Value: value, Shorthand: true}, true
+
+	case token.INT, token.STRING, token.TRUE, token.FALSE:
+		key := parser.prefixParseFns[tok.Type]()
+		if key == nil || !parser.advanceToExpectedToken(token.COLON) {
+			return ast.HashPatternPair{}, false
+		}
+
+		parser.advanceTokens()
+		value := parser.parsePattern()
+		if value == nil {
+			return ast.HashPatternPair{}, false
+		}
+
+		return ast.HashPatternPair{Key: key, Value: value}, true
+	}
+
+	parser.patternError("hash pattern key")
+	return ast.HashPatternPair{}, false
+}
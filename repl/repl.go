@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"monkey/ast"
 	"monkey/eval"
 	"monkey/lexer"
 	"monkey/object"
@@ -15,6 +16,7 @@ const PROMPT = ">> "
 func Start(in io.Reader, out io.Writer) {
 	scanner := bufio.NewScanner(in)
 	env := object.NewEnvironment()
+	interpreter := &eval.Interpreter{}
 
 	for {
 		fmt.Fprint(out, PROMPT)
@@ -35,14 +37,29 @@ func Start(in io.Reader, out io.Writer) {
 			continue
 		}
 
-		value := eval.Eval(program, env)
+		value := interpreter.Eval(program, env)
 
-		if value != nil {
+		if value.Type() == object.ERROR_OBJECT || !isDeclaration(program) {
 			fmt.Fprintf(out, "%+v\n", value.Inspect())
 		}
 	}
 }
 
+// isDeclaration reports whether the last statement of program only binds a
+// name, its value is not worth printing.
+func isDeclaration(program *ast.Program) bool {
+	if len(program.Statements) == 0 {
+		return true
+	}
+
+	switch program.Statements[len(program.Statements)-1].(type) {
+	case *ast.LetStatement, *ast.FunctionStatement, *ast.ImportStatement, *ast.ExportStatement:
+		return true
+	default:
+		return false
+	}
+}
+
 func outputErrors(out io.Writer, errors []string) {
 	fmt.Fprintf(out, "😅 Ooops ... we encountered some errors:\n")
 
@@ -1,19 +1,36 @@
 package token
 
+import "fmt"
+
 type TokenType string
 
 type Token struct {
-	Type    TokenType
-	Literal string
+	Type     TokenType
+	Literal  string
+	Position Position
+}
+
+// Position points to the first character of a token in the source input.
+// Lines and columns start at 1, columns are counted in bytes.
+type Position struct {
+	Line   int
+	Column int
+}
+
+func (position Position) String() string {
+	return fmt.Sprintf("%d:%d", position.Line, position.Column)
 }
 
 const (
 	ILLEGAL TokenType = "ILLEGAL"
 	EOF     TokenType = "EOF"
+	COMMENT TokenType = "COMMENT" // from // to the end of the line
 
 	// Identifiers + literals
-	IDENT TokenType = "IDENT" // add, foobar, x, y, ...
-	INT   TokenType = "INT"
+	IDENT  TokenType = "IDENT" // add, foobar, x, y, ...
+	INT    TokenType = "INT"
+	FLOAT  TokenType = "FLOAT"  // digits with a fraction, e.g. 1.5
+	STRING TokenType = "STRING" // the literal holds the text between the quotes
 
 	// Operators
 	ASSIGN   TokenType = "="
@@ -29,14 +46,27 @@ const (
 	EQ     TokenType = "=="
 	NOT_EQ TokenType = "!="
 
+	NULLISH  TokenType = "??"
+	QUESTION TokenType = "?"
+	ARROW    TokenType = "=>"
+
 	// Delimiters
 	COMMA     TokenType = ","
 	SEMICOLON TokenType = ";"
+	COLON     TokenType = ":"
+	DOT       TokenType = "."
+
+	QUESTION_DOT     TokenType = "?."
+	QUESTION_BRACKET TokenType = "?["
+
+	LPAREN   TokenType = "("
+	RPAREN   TokenType = ")"
+	LBRACE   TokenType = "{"
+	RBRACE   TokenType = "}"
+	LBRACKET TokenType = "["
+	RBRACKET TokenType = "]"
 
-	LPAREN TokenType = "("
-	RPAREN TokenType = ")"
-	LBRACE TokenType = "{"
-	RBRACE TokenType = "}"
+	ELLIPSIS TokenType = "..."
 
 	// Keywords
 	FUNCTION TokenType = "FUNCTION"
@@ -46,6 +76,14 @@ const (
 	IF       TokenType = "IF"
 	ELSE     TokenType = "ELSE"
 	RETURN   TokenType = "RETURN"
+	NULL     TokenType = "NULL"
+	MATCH    TokenType = "MATCH"
+	IMPORT   TokenType = "IMPORT"
+	EXPORT   TokenType = "EXPORT"
+	THROW    TokenType = "THROW"
+	TRY      TokenType = "TRY"
+	CATCH    TokenType = "CATCH"
+	FINALLY  TokenType = "FINALLY"
 )
 
 var oneCharTokens = map[byte]TokenType{
@@ -54,10 +92,15 @@ var oneCharTokens = map[byte]TokenType{
 	'+': PLUS,
 	',': COMMA,
 	';': SEMICOLON,
+	':': COLON,
+	'?': QUESTION,
+	'.': DOT,
 	'(': LPAREN,
 	')': RPAREN,
 	'{': LBRACE,
 	'}': RBRACE,
+	'[': LBRACKET,
+	']': RBRACKET,
 	'-': MINUS,
 	'!': BANG,
 	'*': ASTERISK,
@@ -77,6 +120,10 @@ func LookupOneCharToken(char byte) (TokenType, bool) {
 var twoCharTokens = map[string]TokenType{
 	"==": EQ,
 	"!=": NOT_EQ,
+	"??": NULLISH,
+	"=>": ARROW,
+	"?.": QUESTION_DOT,
+	"?[": QUESTION_BRACKET,
 }
 
 func LookupTwoCharToken(chars string) (TokenType, bool) {
@@ -87,14 +134,34 @@ func LookupTwoCharToken(chars string) (TokenType, bool) {
 	return ILLEGAL, false
 }
 
+var threeCharTokens = map[string]TokenType{
+	"...": ELLIPSIS,
+}
+
+func LookupThreeCharToken(chars string) (TokenType, bool) {
+	if tokenType, ok := threeCharTokens[chars]; ok {
+		return tokenType, true
+	}
+
+	return ILLEGAL, false
+}
+
 var keywords = map[string]TokenType{
-	"fn":     FUNCTION,
-	"let":    LET,
-	"true":   TRUE,
-	"false":  FALSE,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
+	"fn":      FUNCTION,
+	"let":     LET,
+	"true":    TRUE,
+	"false":   FALSE,
+	"if":      IF,
+	"else":    ELSE,
+	"return":  RETURN,
+	"null":    NULL,
+	"match":   MATCH,
+	"import":  IMPORT,
+	"export":  EXPORT,
+	"throw":   THROW,
+	"try":     TRY,
+	"catch":   CATCH,
+	"finally": FINALLY,
 }
 
 func LookupIdentifier(identifier string) TokenType {